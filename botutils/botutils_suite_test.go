@@ -0,0 +1,13 @@
+package botutils_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestBotutils(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Botutils Suite")
+}
@@ -0,0 +1,260 @@
+package botutils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/pkg/errors"
+	"github.com/solo-io/go-utils/contextutils"
+	"go.uber.org/zap"
+)
+
+// A multi-repo release (tag repo A, wait for its images, bump repo B, publish the chart, ...) is modeled as an
+// ordered list of steps. The orchestrator persists which steps are done after every transition, so a release
+// that failed, or is waiting on something, resumes at the same step the next time Advance is called, e.g. on
+// the next webhook event or when someone asks the bot to retry.
+//
+// Advance may be called again before an earlier call has finished, e.g. for two webhook events arriving at once.
+// The orchestrator saves before running each step to claim it, and every save is checked against the version of
+// the state it was loaded from, so only one of the calls runs the step; the others stop with ReleaseConflictError.
+// A call that finds a step running stops the same way, unless the claim is older than ClaimTimeout.
+
+// ReleaseConflictError is returned, wrapped, by Advance when the state of the release was saved by another call
+// since it was loaded; the other call carries on with the release, so it can be ignored
+var ReleaseConflictError = errors.New("the release state was saved concurrently")
+
+type ReleaseStatus string
+
+const (
+	ReleaseStatusRunning ReleaseStatus = "running"
+	// a step is waiting on something outside of the bot, such as an image being published
+	ReleaseStatusWaiting ReleaseStatus = "waiting"
+	ReleaseStatusFailed  ReleaseStatus = "failed"
+	ReleaseStatusDone    ReleaseStatus = "done"
+)
+
+// ReleaseStep is one step of a release. Run returns done=false if the step can't complete yet (e.g. the
+// images it waits for don't exist yet); it is called again the next time the release is advanced.
+// A step only runs again after it failed, was waiting, or the bot stopped while running it, so it should be
+// safe to retry, e.g. by checking whether the tag it creates already exists.
+// Steps can record values for later steps, such as the tag they created, in state.Values.
+type ReleaseStep struct {
+	Name string
+	Run  func(ctx context.Context, state *ReleaseState) (done bool, err error)
+}
+
+// ReleaseState is the persisted progress of a release
+type ReleaseState struct {
+	Release   string            `json:"release"`
+	Status    ReleaseStatus     `json:"status"`
+	Completed []string          `json:"completed"`
+	Values    map[string]string `json:"values"`
+	LastError string            `json:"lastError,omitempty"`
+	UpdatedAt time.Time         `json:"updatedAt"`
+	// incremented on every save, see ReleaseStateStore
+	Version int `json:"version"`
+}
+
+func (s *ReleaseState) isCompleted(step string) bool {
+	for _, completed := range s.Completed {
+		if completed == step {
+			return true
+		}
+	}
+	return false
+}
+
+// ReleaseStateStore persists the state of a release between runs of the bot
+type ReleaseStateStore interface {
+	// Load returns nil if the release hasn't started yet
+	Load(ctx context.Context) (*ReleaseState, error)
+	// Save returns ReleaseConflictError, without saving state, if the stored state isn't the version before it,
+	// i.e. the state was saved since it was loaded. The release hasn't started yet at version 0.
+	Save(ctx context.Context, state *ReleaseState) error
+}
+
+// ReleaseStatusReporter tells people following the release about its progress
+type ReleaseStatusReporter interface {
+	ReportStatus(ctx context.Context, state *ReleaseState, message string) error
+}
+
+// DefaultReleaseClaimTimeout is the ClaimTimeout used when none is set
+const DefaultReleaseClaimTimeout = 30 * time.Minute
+
+type ReleaseOrchestrator struct {
+	Release string
+	Steps   []ReleaseStep
+	Store   ReleaseStateStore
+	// optional
+	Reporter ReleaseStatusReporter
+	// how long a running step keeps other calls from advancing the release, after which the bot is assumed to
+	// have stopped while running it; DefaultReleaseClaimTimeout if not set
+	ClaimTimeout time.Duration
+}
+
+// Advance runs the steps of the release that haven't completed yet, in order, until one of them fails or
+// can't complete yet, and returns the resulting state. A failed step is retried by calling Advance again.
+func (o *ReleaseOrchestrator) Advance(ctx context.Context) (*ReleaseState, error) {
+	state, err := o.Store.Load(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "loading state of release %s", o.Release)
+	}
+	if state == nil {
+		state = &ReleaseState{Release: o.Release}
+	}
+	if state.Values == nil {
+		state.Values = map[string]string{}
+	}
+	if state.Status == ReleaseStatusDone {
+		return state, nil
+	}
+	if state.Status == ReleaseStatusRunning && time.Since(state.UpdatedAt) < o.claimTimeout() {
+		return state, errors.Wrapf(ReleaseConflictError, "release %s is being advanced", o.Release)
+	}
+
+	for _, step := range o.Steps {
+		if state.isCompleted(step.Name) {
+			continue
+		}
+		previousStatus := state.Status
+		state.Status = ReleaseStatusRunning
+		// claim the step, so that a concurrent call running it fails to save
+		if err := o.save(ctx, state); err != nil {
+			return state, err
+		}
+		done, err := step.Run(ctx, state)
+		switch {
+		case err != nil:
+			state.Status = ReleaseStatusFailed
+			state.LastError = err.Error()
+			contextutils.LoggerFrom(ctx).Errorw("Release step failed", zap.Error(err),
+				zap.String("release", o.Release), zap.String("step", step.Name))
+			return state, o.transition(ctx, state, fmt.Sprintf("Step `%s` failed: %v", step.Name, err))
+		case !done:
+			state.Status = ReleaseStatusWaiting
+			if previousStatus == ReleaseStatusWaiting {
+				// don't report the same wait every time the release is advanced
+				return state, o.save(ctx, state)
+			}
+			return state, o.transition(ctx, state, fmt.Sprintf("Waiting on step `%s`", step.Name))
+		}
+		state.LastError = ""
+		state.Completed = append(state.Completed, step.Name)
+		if err := o.transition(ctx, state, fmt.Sprintf("Completed step `%s`", step.Name)); err != nil {
+			return state, err
+		}
+	}
+
+	state.Status = ReleaseStatusDone
+	return state, o.transition(ctx, state, fmt.Sprintf("Release %s is done", o.Release))
+}
+
+func (o *ReleaseOrchestrator) claimTimeout() time.Duration {
+	if o.ClaimTimeout > 0 {
+		return o.ClaimTimeout
+	}
+	return DefaultReleaseClaimTimeout
+}
+
+func (o *ReleaseOrchestrator) transition(ctx context.Context, state *ReleaseState, message string) error {
+	if err := o.save(ctx, state); err != nil {
+		return err
+	}
+	if o.Reporter == nil {
+		return nil
+	}
+	if err := o.Reporter.ReportStatus(ctx, state, message); err != nil {
+		// the state was saved, so the release can still make progress
+		contextutils.LoggerFrom(ctx).Warnw("Unable to report release status", zap.Error(err), zap.String("release", o.Release))
+	}
+	return nil
+}
+
+func (o *ReleaseOrchestrator) save(ctx context.Context, state *ReleaseState) error {
+	state.UpdatedAt = time.Now().UTC()
+	state.Version++
+	if err := o.Store.Save(ctx, state); err != nil {
+		state.Version--
+		return errors.Wrapf(err, "saving state of release %s", o.Release)
+	}
+	return nil
+}
+
+var issueStateRegex = regexp.MustCompile(`(?s)<!-- release-state: (.*?) -->`)
+
+// IssueReleaseStore persists the state of a release in the body of a GitHub issue, which doubles as a dashboard
+// for the release: the body shows a checklist of the steps, and every transition is posted as a comment.
+// The store owns the issue body and overwrites it on every save. Save re-reads the issue to check the version
+// of the stored state; GitHub can't make the edit conditional on it, so two saves within the same moment can still
+// both succeed, but a step that takes longer than the round trip isn't run twice.
+type IssueReleaseStore struct {
+	Client *github.Client
+	Owner  string
+	Repo   string
+	Issue  int
+	// used to render the checklist
+	Steps []ReleaseStep
+}
+
+var _ ReleaseStateStore = &IssueReleaseStore{}
+var _ ReleaseStatusReporter = &IssueReleaseStore{}
+
+func (s *IssueReleaseStore) Load(ctx context.Context) (*ReleaseState, error) {
+	issue, _, err := s.Client.Issues.Get(ctx, s.Owner, s.Repo, s.Issue)
+	if err != nil {
+		return nil, err
+	}
+	match := issueStateRegex.FindStringSubmatch(issue.GetBody())
+	if match == nil {
+		return nil, nil
+	}
+	var state ReleaseState
+	if err := json.Unmarshal([]byte(match[1]), &state); err != nil {
+		return nil, errors.Wrapf(err, "parsing release state in issue %d", s.Issue)
+	}
+	return &state, nil
+}
+
+func (s *IssueReleaseStore) Save(ctx context.Context, state *ReleaseState) error {
+	stored, err := s.Load(ctx)
+	if err != nil {
+		return err
+	}
+	storedVersion := 0
+	if stored != nil {
+		storedVersion = stored.Version
+	}
+	if storedVersion != state.Version-1 {
+		return ReleaseConflictError
+	}
+	// json.Marshal escapes '>', so the state can't end the HTML comment early
+	encoded, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	body := &strings.Builder{}
+	fmt.Fprintf(body, "<!-- release-state: %s -->\n", encoded)
+	fmt.Fprintf(body, "Release **%s** is %s.\n\n", state.Release, state.Status)
+	for _, step := range s.Steps {
+		check := " "
+		if state.isCompleted(step.Name) {
+			check = "x"
+		}
+		fmt.Fprintf(body, "- [%s] %s\n", check, step.Name)
+	}
+	if state.LastError != "" {
+		fmt.Fprintf(body, "\nLast error:\n```\n%s\n```\n", state.LastError)
+	}
+	_, _, err = s.Client.Issues.Edit(ctx, s.Owner, s.Repo, s.Issue, &github.IssueRequest{Body: github.String(body.String())})
+	return err
+}
+
+func (s *IssueReleaseStore) ReportStatus(ctx context.Context, state *ReleaseState, message string) error {
+	_, _, err := s.Client.Issues.CreateComment(ctx, s.Owner, s.Repo, s.Issue, &github.IssueComment{Body: github.String(message)})
+	return err
+}
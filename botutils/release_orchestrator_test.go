@@ -0,0 +1,184 @@
+package botutils_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/solo-io/go-utils/botutils"
+)
+
+var _ = Describe("ReleaseOrchestrator", func() {
+	var (
+		ctx          = context.Background()
+		server       *httptest.Server
+		lock         sync.Mutex
+		body         string
+		comments     []string
+		imagesPushed bool
+		bumpFails    bool
+		ran          []string
+		// if set, tagging is closed when the tag step starts, which then waits for untag to be closed
+		tagging, untag chan struct{}
+		store          *botutils.IssueReleaseStore
+		orchestrator   *botutils.ReleaseOrchestrator
+	)
+
+	BeforeEach(func() {
+		body, comments, ran = "Tracking issue for the 1.5 release", nil, nil
+		imagesPushed, bumpFails = false, false
+		tagging, untag = nil, nil
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/solo-io/releases/issues/1", func(w http.ResponseWriter, r *http.Request) {
+			lock.Lock()
+			defer lock.Unlock()
+			if r.Method == http.MethodPatch {
+				var req github.IssueRequest
+				Expect(json.NewDecoder(r.Body).Decode(&req)).To(Succeed())
+				body = req.GetBody()
+			}
+			json.NewEncoder(w).Encode(&github.Issue{Number: github.Int(1), Body: github.String(body)})
+		})
+		mux.HandleFunc("/repos/solo-io/releases/issues/1/comments", func(w http.ResponseWriter, r *http.Request) {
+			lock.Lock()
+			defer lock.Unlock()
+			b, _ := ioutil.ReadAll(r.Body)
+			var comment github.IssueComment
+			Expect(json.Unmarshal(b, &comment)).To(Succeed())
+			comments = append(comments, comment.GetBody())
+			fmt.Fprint(w, `{}`)
+		})
+		server = httptest.NewServer(mux)
+		client := github.NewClient(nil)
+		client.BaseURL, _ = url.Parse(server.URL + "/")
+
+		steps := []botutils.ReleaseStep{
+			{
+				Name: "tag gloo",
+				Run: func(ctx context.Context, state *botutils.ReleaseState) (bool, error) {
+					ran = append(ran, "tag gloo")
+					if tagging != nil {
+						close(tagging)
+						<-untag
+					}
+					state.Values["tag"] = "v1.5.0"
+					return true, nil
+				},
+			},
+			{
+				Name: "wait for images",
+				Run: func(ctx context.Context, state *botutils.ReleaseState) (bool, error) {
+					ran = append(ran, "wait for images")
+					return imagesPushed, nil
+				},
+			},
+			{
+				Name: "bump gloo-ee",
+				Run: func(ctx context.Context, state *botutils.ReleaseState) (bool, error) {
+					ran = append(ran, "bump gloo-ee to "+state.Values["tag"])
+					if bumpFails {
+						return false, errors.New("merge conflict")
+					}
+					return true, nil
+				},
+			},
+		}
+		store = &botutils.IssueReleaseStore{Client: client, Owner: "solo-io", Repo: "releases", Issue: 1, Steps: steps}
+		orchestrator = &botutils.ReleaseOrchestrator{Release: "1.5", Steps: steps, Store: store, Reporter: store}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("runs the steps in order, resuming where it stopped", func() {
+		state, err := orchestrator.Advance(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state.Status).To(Equal(botutils.ReleaseStatusWaiting))
+		Expect(body).To(ContainSubstring("- [x] tag gloo\n- [ ] wait for images\n"))
+
+		// still waiting, so only the waiting step runs and nothing new is reported
+		_, err = orchestrator.Advance(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(comments).To(Equal([]string{"Completed step `tag gloo`", "Waiting on step `wait for images`"}))
+
+		imagesPushed = true
+		state, err = orchestrator.Advance(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state.Status).To(Equal(botutils.ReleaseStatusDone))
+		Expect(ran).To(Equal([]string{"tag gloo", "wait for images", "wait for images", "wait for images", "bump gloo-ee to v1.5.0"}))
+		Expect(comments[len(comments)-1]).To(Equal("Release 1.5 is done"))
+	})
+
+	It("retries a failed step", func() {
+		imagesPushed, bumpFails = true, true
+		state, err := orchestrator.Advance(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state.Status).To(Equal(botutils.ReleaseStatusFailed))
+		Expect(state.LastError).To(Equal("merge conflict"))
+		Expect(body).To(ContainSubstring("merge conflict"))
+		Expect(comments[len(comments)-1]).To(Equal("Step `bump gloo-ee` failed: merge conflict"))
+
+		bumpFails = false
+		state, err = orchestrator.Advance(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state.Status).To(Equal(botutils.ReleaseStatusDone))
+		Expect(state.LastError).To(BeEmpty())
+		Expect(ran).To(Equal([]string{"tag gloo", "wait for images", "bump gloo-ee to v1.5.0", "bump gloo-ee to v1.5.0"}))
+	})
+	It("doesn't run a step that an overlapping call is running", func() {
+		tagging, untag = make(chan struct{}), make(chan struct{})
+		advanced := make(chan error)
+		go func() {
+			defer GinkgoRecover()
+			_, err := orchestrator.Advance(ctx)
+			advanced <- err
+		}()
+		Eventually(tagging).Should(BeClosed())
+
+		_, err := orchestrator.Advance(ctx)
+		Expect(errors.Cause(err)).To(Equal(botutils.ReleaseConflictError))
+
+		close(untag)
+		Eventually(advanced).Should(Receive(BeNil()))
+		Expect(ran).To(Equal([]string{"tag gloo", "wait for images"}))
+	})
+
+	It("resumes a step that was claimed longer ago than the claim timeout", func() {
+		tagging, untag = make(chan struct{}), make(chan struct{})
+		go orchestrator.Advance(ctx)
+		Eventually(tagging).Should(BeClosed())
+
+		// the first call is stuck, as if the bot had stopped
+		tagging = nil
+		orchestrator.ClaimTimeout = time.Nanosecond
+		state, err := orchestrator.Advance(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state.Status).To(Equal(botutils.ReleaseStatusWaiting))
+		Expect(ran).To(Equal([]string{"tag gloo", "tag gloo", "wait for images"}))
+		close(untag)
+	})
+
+	It("doesn't save over state saved since it was loaded", func() {
+		state := &botutils.ReleaseState{Release: "1.5", Status: botutils.ReleaseStatusRunning, Version: 1}
+		Expect(store.Save(ctx, state)).To(Succeed())
+		Expect(store.Save(ctx, state)).To(Equal(botutils.ReleaseConflictError))
+
+		state.Version = 2
+		Expect(store.Save(ctx, state)).To(Succeed())
+		loaded, err := store.Load(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded.Version).To(Equal(2))
+	})
+})
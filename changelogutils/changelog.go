@@ -24,6 +24,8 @@ type ChangelogEntry struct {
 	DependencyRepo  string             `json:"dependencyRepo,omitempty"`
 	DependencyTag   string             `json:"dependencyTag,omitempty"`
 	ResolvesIssue   *bool              `json:"resolvesIssue,omitempty"`
+	// Link to the approval for adding this entry to a frozen version, see ValidationSettings.FrozenVersions
+	Exception string `json:"exception,omitempty"`
 }
 
 func (c *ChangelogEntry) GetResolvesIssue() bool {
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/solo-io/go-utils/changelogutils"
+	"github.com/solo-io/go-utils/log"
+	"github.com/solo-io/go-utils/versionutils"
+	"github.com/spf13/afero"
+)
+
+// Scaffolds a changelog entry for the current branch, asking for anything that isn't passed as a flag:
+//
+//	go run github.com/solo-io/go-utils/changelogutils/cmd/scaffold -type FIX -issue https://github.com/solo-io/go-utils/issues/1
+func main() {
+	var (
+		opts      changelogutils.ScaffoldOptions
+		entryType string
+		root      string
+		latestTag string
+	)
+	flag.StringVar(&root, "root", ".", "root of the repo, which contains the changelog directory")
+	flag.StringVar(&opts.FileName, "name", "", "name of the changelog file (default: the current branch)")
+	flag.StringVar(&opts.Version, "version", "", "version to add the entry to (default: the next version)")
+	flag.StringVar(&latestTag, "latest-tag", "", "latest released tag (default: the latest tag in the repo)")
+	flag.StringVar(&entryType, "type", "", "type of the entry, e.g. FIX or NEW_FEATURE")
+	flag.StringVar(&opts.Entry.Description, "description", "", "description of the change")
+	flag.StringVar(&opts.Entry.IssueLink, "issue", "", "link to the issue the change addresses")
+	flag.Parse()
+
+	if opts.FileName == "" {
+		opts.FileName = git(root, "rev-parse", "--abbrev-ref", "HEAD")
+	}
+	if latestTag == "" {
+		latestTag = git(root, "describe", "--tags", "--abbrev=0")
+		if latestTag == "" {
+			latestTag = versionutils.SemverNilVersionValue
+		}
+	}
+	if err := changelogutils.PromptForChangelogEntry(entryType, &opts.Entry); err != nil {
+		log.Fatalf("unable to read changelog entry: %v", err)
+	}
+	path, err := changelogutils.ScaffoldChangelogEntry(afero.NewOsFs(), root, latestTag, opts)
+	if err != nil {
+		log.Fatalf("unable to scaffold changelog entry: %v", err)
+	}
+	fmt.Printf("Wrote %s\n", path)
+}
+
+// git returns the trimmed output of a git command, or "" if it fails, e.g. because the repo has no tags yet
+func git(dir string, args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
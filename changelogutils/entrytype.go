@@ -67,10 +67,19 @@ func (clt *ChangelogEntryType) UnmarshalJSON(data []byte) error {
 	if err := json.Unmarshal(data, &s); err != nil {
 		return fmt.Errorf("ChangelogEntryType should be a string, got %s", data)
 	}
-	v, ok := _ChangelogEntryTypeToValue[s]
-	if !ok {
-		return fmt.Errorf("invalid ChangelogEntryType %q", s)
+	v, err := ParseChangelogEntryType(s)
+	if err != nil {
+		return err
 	}
 	*clt = v
 	return nil
 }
+
+// ParseChangelogEntryType returns the type with the given name, e.g. "NEW_FEATURE"
+func ParseChangelogEntryType(s string) (ChangelogEntryType, error) {
+	v, ok := _ChangelogEntryTypeToValue[s]
+	if !ok {
+		return 0, fmt.Errorf("invalid ChangelogEntryType %q", s)
+	}
+	return v, nil
+}
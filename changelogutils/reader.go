@@ -110,26 +110,33 @@ func (c *changelogReader) ReadChangelogFile(ctx context.Context, path string) (*
 	}
 
 	for _, entry := range changelog.Entries {
-		if entry.Type != NON_USER_FACING && entry.Type != DEPENDENCY_BUMP {
-			if entry.IssueLink == "" {
-				return nil, MissingIssueLinkError
-			}
-			if entry.Description == "" {
-				return nil, MissingDescriptionError
-			}
-		}
-		if entry.Type == DEPENDENCY_BUMP {
-			if entry.DependencyOwner == "" {
-				return nil, MissingOwnerError
-			}
-			if entry.DependencyRepo == "" {
-				return nil, MissingRepoError
-			}
-			if entry.DependencyTag == "" {
-				return nil, MissingTagError
-			}
+		if err := validateChangelogEntry(entry); err != nil {
+			return nil, err
 		}
 	}
 
 	return &changelog, nil
 }
+
+func validateChangelogEntry(entry *ChangelogEntry) error {
+	if entry.Type != NON_USER_FACING && entry.Type != DEPENDENCY_BUMP {
+		if entry.IssueLink == "" {
+			return MissingIssueLinkError
+		}
+		if entry.Description == "" {
+			return MissingDescriptionError
+		}
+	}
+	if entry.Type == DEPENDENCY_BUMP {
+		if entry.DependencyOwner == "" {
+			return MissingOwnerError
+		}
+		if entry.DependencyRepo == "" {
+			return MissingRepoError
+		}
+		if entry.DependencyTag == "" {
+			return MissingTagError
+		}
+	}
+	return nil
+}
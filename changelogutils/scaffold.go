@@ -0,0 +1,165 @@
+package changelogutils
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	"github.com/rotisserie/eris"
+	"github.com/solo-io/go-utils/surveyutils"
+	"github.com/solo-io/go-utils/versionutils"
+	"github.com/spf13/afero"
+)
+
+var (
+	InvalidChangelogFileNameError = func(name string) error {
+		return eris.Errorf("%q can't be used as the name of a changelog file", name)
+	}
+	UnableToWriteChangelogFileError = func(err error, path string) error {
+		return errors.Wrapf(err, "Unable to write changelog file %s", path)
+	}
+)
+
+var unsafeFileNameChars = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+type ScaffoldOptions struct {
+	// the name of the changelog file, usually the name of the branch, e.g. "fix/retry-uploads" is written
+	// to fix-retry-uploads.yaml. If the file already exists, the entry is added to it.
+	FileName string
+	// the version to add the entry to. If empty, the unreleased version in the changelog directory is used,
+	// or, if there is none yet, the version after latestTag that the type of the entry calls for.
+	Version string
+	Entry   ChangelogEntry
+}
+
+// ScaffoldChangelogEntry writes a changelog file with opts.Entry to the changelog directory under
+// changelogParentPath, returning the path of the file. The entry is validated the same way changelogs are
+// when they are read, so that malformed entries are caught before they reach review.
+func ScaffoldChangelogEntry(fs afero.Fs, changelogParentPath, latestTag string, opts ScaffoldOptions) (string, error) {
+	if err := validateChangelogEntry(&opts.Entry); err != nil {
+		return "", err
+	}
+	fileName, err := changelogFileName(opts.FileName)
+	if err != nil {
+		return "", err
+	}
+	version := opts.Version
+	if version == "" {
+		version, err = nextChangelogVersion(fs, changelogParentPath, latestTag, opts.Entry.Type)
+		if err != nil {
+			return "", err
+		}
+	}
+	if !versionutils.MatchesRegex(version) {
+		return "", newErrorInvalidDirectoryName(version)
+	}
+
+	path := filepath.Join(changelogParentPath, ChangelogDirectory, version, fileName)
+	changelogFile := &ChangelogFile{}
+	if exists, err := afero.Exists(fs, path); err != nil {
+		return "", err
+	} else if exists {
+		changelogFile, err = ReadChangelogFile(fs, path)
+		if err != nil {
+			return "", err
+		}
+	}
+	changelogFile.Entries = append(changelogFile.Entries, &opts.Entry)
+
+	bytes, err := yaml.Marshal(changelogFile)
+	if err != nil {
+		return "", err
+	}
+	if err := fs.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", UnableToWriteChangelogFileError(err, path)
+	}
+	if err := afero.WriteFile(fs, path, bytes, 0644); err != nil {
+		return "", UnableToWriteChangelogFileError(err, path)
+	}
+	return path, nil
+}
+
+func changelogFileName(name string) (string, error) {
+	sanitized := strings.TrimSuffix(strings.ToLower(name), ".yaml")
+	sanitized = strings.Trim(unsafeFileNameChars.ReplaceAllString(sanitized, "-"), "-.")
+	if sanitized == "" {
+		return "", InvalidChangelogFileNameError(name)
+	}
+	return sanitized + ".yaml", nil
+}
+
+func nextChangelogVersion(fs afero.Fs, changelogParentPath, latestTag string, entryType ChangelogEntryType) (string, error) {
+	exists, err := ChangelogDirExists(fs, changelogParentPath)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		proposedTag, err := GetProposedTag(fs, latestTag, changelogParentPath)
+		if err == nil {
+			return proposedTag, nil
+		}
+		if !IsNoVersionFoundError(err) {
+			return "", err
+		}
+	}
+	if latestTag == versionutils.SemverNilVersionValue {
+		return versionutils.SemverMinimumVersion, nil
+	}
+	latestVersion, err := versionutils.ParseVersion(latestTag)
+	if err != nil {
+		return "", err
+	}
+	return latestVersion.IncrementVersion(entryType.BreakingChange(), entryType.NewFeature()).String(), nil
+}
+
+// PromptForChangelogEntry sets entry.Type to the type named entryType, e.g. "FIX", and asks for the fields of
+// entry that the type requires and aren't set yet. If entryType is empty, the type is asked for as well.
+func PromptForChangelogEntry(entryType string, entry *ChangelogEntry) error {
+	if entryType == "" {
+		if err := surveyutils.ChooseFromList("What kind of change is this?", &entryType, changelogEntryTypeNames()); err != nil {
+			return err
+		}
+	}
+	parsed, err := ParseChangelogEntryType(entryType)
+	if err != nil {
+		return err
+	}
+	entry.Type = parsed
+	if entry.Type == DEPENDENCY_BUMP {
+		if err := promptIfEmpty("Owner of the dependency", &entry.DependencyOwner); err != nil {
+			return err
+		}
+		if err := promptIfEmpty("Repo of the dependency", &entry.DependencyRepo); err != nil {
+			return err
+		}
+		if err := promptIfEmpty("New tag of the dependency", &entry.DependencyTag); err != nil {
+			return err
+		}
+	}
+	if err := promptIfEmpty("Description", &entry.Description); err != nil {
+		return err
+	}
+	if entry.Type != NON_USER_FACING && entry.Type != DEPENDENCY_BUMP {
+		if err := promptIfEmpty("Issue link", &entry.IssueLink); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func promptIfEmpty(msg string, value *string) error {
+	if *value != "" {
+		return nil
+	}
+	return surveyutils.GetStringInput(msg, value)
+}
+
+func changelogEntryTypeNames() []string {
+	var names []string
+	for entryType := BREAKING_CHANGE; entryType <= UPGRADE; entryType++ {
+		names = append(names, entryType.String())
+	}
+	return names
+}
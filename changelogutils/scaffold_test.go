@@ -0,0 +1,76 @@
+package changelogutils_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/solo-io/go-utils/changelogutils"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("ScaffoldChangelogEntry", func() {
+	var (
+		fs  afero.Fs
+		fix = changelogutils.ChangelogEntry{
+			Type:        changelogutils.FIX,
+			Description: "Retry uploads.",
+			IssueLink:   "https://github.com/solo-io/go-utils/issues/1",
+		}
+	)
+
+	BeforeEach(func() {
+		fs = afero.NewMemMapFs()
+	})
+
+	readEntries := func(path string) []*changelogutils.ChangelogEntry {
+		changelogFile, err := changelogutils.ReadChangelogFile(fs, path)
+		Expect(err).NotTo(HaveOccurred())
+		return changelogFile.Entries
+	}
+
+	It("writes the entry to the next version named after the branch", func() {
+		path, err := changelogutils.ScaffoldChangelogEntry(fs, "", "v1.2.3", changelogutils.ScaffoldOptions{
+			FileName: "Fix/Retry Uploads",
+			Entry:    fix,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(path).To(Equal("changelog/v1.2.4/fix-retry-uploads.yaml"))
+		Expect(readEntries(path)).To(Equal([]*changelogutils.ChangelogEntry{&fix}))
+	})
+
+	It("bumps the version the type of the entry calls for", func() {
+		feature := changelogutils.ChangelogEntry{Type: changelogutils.NEW_FEATURE, Description: "Add things.", IssueLink: "https://github.com/solo-io/go-utils/issues/2"}
+		path, err := changelogutils.ScaffoldChangelogEntry(fs, "", "v1.2.3", changelogutils.ScaffoldOptions{FileName: "feature", Entry: feature})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(path).To(Equal("changelog/v1.3.0/feature.yaml"))
+	})
+
+	It("uses the unreleased version that already exists", func() {
+		Expect(fs.MkdirAll("changelog/v1.3.0", 0755)).To(Succeed())
+		Expect(fs.MkdirAll("changelog/v1.2.3", 0755)).To(Succeed())
+		path, err := changelogutils.ScaffoldChangelogEntry(fs, "", "v1.2.3", changelogutils.ScaffoldOptions{FileName: "fix", Entry: fix})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(path).To(Equal("changelog/v1.3.0/fix.yaml"))
+	})
+
+	It("adds entries to an existing file", func() {
+		opts := changelogutils.ScaffoldOptions{FileName: "fix.yaml", Version: "v1.2.4", Entry: fix}
+		_, err := changelogutils.ScaffoldChangelogEntry(fs, "", "v1.2.3", opts)
+		Expect(err).NotTo(HaveOccurred())
+		opts.Entry = changelogutils.ChangelogEntry{Type: changelogutils.NON_USER_FACING}
+		path, err := changelogutils.ScaffoldChangelogEntry(fs, "", "v1.2.3", opts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(readEntries(path)).To(HaveLen(2))
+	})
+
+	It("rejects entries that wouldn't pass validation", func() {
+		invalid := fix
+		invalid.IssueLink = ""
+		_, err := changelogutils.ScaffoldChangelogEntry(fs, "", "v1.2.3", changelogutils.ScaffoldOptions{FileName: "fix", Entry: invalid})
+		Expect(err).To(Equal(changelogutils.MissingIssueLinkError))
+	})
+
+	It("rejects file names without usable characters", func() {
+		_, err := changelogutils.ScaffoldChangelogEntry(fs, "", "v1.2.3", changelogutils.ScaffoldOptions{FileName: "///", Entry: fix})
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -3,6 +3,7 @@ package changelogutils
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"path/filepath"
 	"strings"
 
@@ -65,6 +66,12 @@ var (
 	InvalidLabelError = func(label string, allowed []string) error {
 		return eris.Errorf("Changelog version has label %s, which isn't in the list of allowed labels: %v", label, allowed)
 	}
+	FrozenVersionError = func(version string) error {
+		return eris.Errorf("Version %s is frozen, new changelog entries must include an exception linking to their approval.", version)
+	}
+	InvalidExceptionLinkError = func(exception string) error {
+		return eris.Errorf("Exception %s must be a link to the approval for the change.", exception)
+	}
 )
 
 type ChangelogValidator interface {
@@ -87,6 +94,10 @@ type ValidationSettings struct {
 
 	// If non-empty, then the validator will reject a changelog if the version's label is not contained in this slice
 	AllowedLabels []string `json:"allowedLabels"`
+
+	// Versions in code freeze. The validator will reject a changelog added to one of these versions unless every
+	// entry has an exception linking to the approval for the change
+	FrozenVersions []string `json:"frozenVersions"`
 }
 
 type changelogValidator struct {
@@ -124,7 +135,7 @@ func (c *changelogValidator) ValidateChangelog(ctx context.Context) (*ChangelogF
 		return nil, err
 	}
 
-	proposedTag, err := c.validateProposedTag(ctx)
+	proposedTag, err := c.validateProposedTag(ctx, newChangelogFile)
 	if err != nil {
 		return nil, err
 	}
@@ -137,7 +148,7 @@ func (c *changelogValidator) ValidateChangelog(ctx context.Context) (*ChangelogF
 	return newChangelogFile, nil
 }
 
-func (c *changelogValidator) validateProposedTag(ctx context.Context) (string, error) {
+func (c *changelogValidator) validateProposedTag(ctx context.Context, added *ChangelogFile) (string, error) {
 	latestTag, err := c.client.FindLatestTagIncludingPrereleaseBeforeSha(ctx, c.base)
 	if err != nil {
 		return "", ListReleasesError(err)
@@ -176,11 +187,11 @@ func (c *changelogValidator) validateProposedTag(ctx context.Context) (string, e
 	if err != nil {
 		return proposedVersion, err
 	}
-	err = c.validateVersionBump(ctx, latestTag, changelog)
+	err = c.validateVersionBump(ctx, latestTag, changelog, added)
 	return proposedVersion, err
 }
 
-func (c *changelogValidator) validateVersionBump(ctx context.Context, latestTag string, changelog *Changelog) error {
+func (c *changelogValidator) validateVersionBump(ctx context.Context, latestTag string, changelog *Changelog, added *ChangelogFile) error {
 	latestVersion, err := versionutils.ParseVersion(latestTag)
 	if err != nil {
 		return err
@@ -206,6 +217,13 @@ func (c *changelogValidator) validateVersionBump(ctx context.Context, latestTag
 		}
 	}
 
+	// If the version is frozen, only entries with an approved exception can be added to it
+	if stringutils.ContainsString(changelog.Version.String(), settings.FrozenVersions) {
+		if err := validateFreezeExceptions(changelog.Version.String(), added); err != nil {
+			return err
+		}
+	}
+
 	for _, file := range changelog.Files {
 		for _, entry := range file.Entries {
 			breakingChanges = breakingChanges || entry.Type.BreakingChange()
@@ -248,6 +266,19 @@ func (c *changelogValidator) validateVersionBump(ctx context.Context, latestTag
 	return nil
 }
 
+func validateFreezeExceptions(version string, added *ChangelogFile) error {
+	for _, entry := range added.Entries {
+		if entry.Exception == "" {
+			return FrozenVersionError(version)
+		}
+		link, err := url.ParseRequestURI(entry.Exception)
+		if err != nil || (link.Scheme != "http" && link.Scheme != "https") || link.Host == "" {
+			return InvalidExceptionLinkError(entry.Exception)
+		}
+	}
+	return nil
+}
+
 func (c *changelogValidator) validateChangelogInPr(ctx context.Context) (*github.CommitFile, *ChangelogFile, error) {
 	changelogFiles, err := GetChangelogFilesAdded(ctx, c.client, c.base, c.code.GetSha())
 	if err != nil {
@@ -721,6 +721,53 @@ var _ = Describe("changelog validator utils", func() {
 			})
 		})
 
+		Context("settings with frozen versions", func() {
+
+			setup := func(contents string) {
+				file1 := github.CommitFile{Filename: &path1, Status: &added}
+				cc := github.CommitsComparison{Files: []*github.CommitFile{&file1}}
+				repoClient.EXPECT().
+					CompareCommits(ctx, base, sha).
+					Return(&cc, nil)
+				code.EXPECT().
+					GetFileContents(ctx, path1).
+					Return([]byte(contents), nil).Times(2)
+				repoClient.EXPECT().
+					FindLatestTagIncludingPrereleaseBeforeSha(ctx, base).
+					Return("v0.5.0", nil)
+				code.EXPECT().
+					ListFiles(ctx, changelogutils.ChangelogDirectory).
+					Return([]os.FileInfo{getChangelogDir(tag)}, nil)
+				code.EXPECT().
+					ListFiles(ctx, filepath.Join(changelogutils.ChangelogDirectory, tag)).
+					Return([]os.FileInfo{&mockFileInfo{name: filename1, isDir: false}}, nil)
+				repoClient.EXPECT().FileExists(ctx, sha, changelogutils.GetValidationSettingsPath()).Return(true, nil)
+				code.EXPECT().GetFileContents(ctx, changelogutils.GetValidationSettingsPath()).Return([]byte(frozenVersionsYaml), nil)
+			}
+
+			It("rejects entries without an exception", func() {
+				setup(validChangelog1)
+				file, err := validator.ValidateChangelog(ctx)
+				Expect(file).To(BeNil())
+				Expect(err.Error()).To(Equal(changelogutils.FrozenVersionError(tag).Error()))
+			})
+
+			It("rejects an exception that isn't a link", func() {
+				setup(invalidExceptionChangelog)
+				file, err := validator.ValidateChangelog(ctx)
+				Expect(file).To(BeNil())
+				Expect(err.Error()).To(Equal(changelogutils.InvalidExceptionLinkError("approved by bob").Error()))
+			})
+
+			It("accepts entries with an exception", func() {
+				setup(exceptionChangelog)
+				file, err := validator.ValidateChangelog(ctx)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(file).NotTo(BeNil())
+				Expect(file.Entries[0].Exception).To(Equal("https://github.com/solo-io/go-utils/issues/1#issuecomment-1"))
+			})
+		})
+
 		Context("invalid settings", func() {
 
 			setup := func() {
@@ -825,5 +872,23 @@ relaxSemverValidation: true
 allowedLabels:
 - beta
 - rc
+`
+	frozenVersionsYaml = `
+frozenVersions:
+- v0.5.1
+`
+	exceptionChangelog = `
+changelog:
+  - type: FIX
+    description: foo1
+    issueLink: bar1
+    exception: https://github.com/solo-io/go-utils/issues/1#issuecomment-1
+`
+	invalidExceptionChangelog = `
+changelog:
+  - type: FIX
+    description: foo1
+    issueLink: bar1
+    exception: approved by bob
 `
 )
@@ -0,0 +1,99 @@
+package cliutils
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/solo-io/go-utils/surveyutils"
+	"github.com/spf13/cobra"
+)
+
+// DefaultAssumeYesEnvVar is the environment variable that, when set to true, confirms every destructive
+// operation, e.g. when a CLI runs in CI
+const DefaultAssumeYesEnvVar = "ASSUME_YES"
+
+// ConfirmOptions controls how ConfirmDestructive asks for confirmation
+type ConfirmOptions struct {
+	// set by --yes, confirms the operation without prompting
+	Yes bool
+	// set by --force, confirms the operation without prompting
+	Force bool
+	// if set, the user can't be prompted, so the operation is refused unless it was confirmed by a flag or the env
+	NonInteractive bool
+	// the env var to check for an override, defaults to DefaultAssumeYesEnvVar
+	EnvVar string
+	// asks the user to confirm, defaults to surveyutils.GetYesInput
+	Prompt func(msg string) (bool, error)
+}
+
+// AddConfirmFlags adds --yes (-y) and --force to the command and all of its subcommands,
+// so that every destructive command of a CLI can be confirmed the same way
+func AddConfirmFlags(cmd *cobra.Command, opts *ConfirmOptions) {
+	flags := cmd.PersistentFlags()
+	flags.BoolVarP(&opts.Yes, "yes", "y", false, Localize(MsgConfirmFlagUsage))
+	flags.BoolVar(&opts.Force, "force", false, Localize(MsgConfirmFlagUsage))
+}
+
+var MsgConfirmFlagUsage = DefineMessage("confirm.flagUsage", "confirm destructive operations without prompting")
+
+type declinedError struct {
+	prompt string
+}
+
+func (e *declinedError) Error() string {
+	return fmt.Sprintf("operation not confirmed: %s", e.prompt)
+}
+
+// IsDeclinedError returns true if the user answered no when asked to confirm
+func IsDeclinedError(err error) bool {
+	_, ok := err.(*declinedError)
+	return ok
+}
+
+type nonInteractiveError struct {
+	prompt string
+	envVar string
+}
+
+func (e *nonInteractiveError) Error() string {
+	return fmt.Sprintf("refusing to run without confirmation: %s. Pass --yes or set %s=true to confirm", e.prompt, e.envVar)
+}
+
+// IsNonInteractiveError returns true if confirmation was required but the user could not be prompted
+func IsNonInteractiveError(err error) bool {
+	_, ok := err.(*nonInteractiveError)
+	return ok
+}
+
+// ConfirmDestructive returns nil if the operation described by prompt may go ahead. It is confirmed without
+// prompting by --yes, --force or the env override; otherwise the user is asked, unless opts.NonInteractive is set.
+// If the operation is not confirmed, the error satisfies IsDeclinedError or IsNonInteractiveError.
+func ConfirmDestructive(prompt string, opts ConfirmOptions) error {
+	envVar := opts.EnvVar
+	if envVar == "" {
+		envVar = DefaultAssumeYesEnvVar
+	}
+	if opts.Yes || opts.Force {
+		return nil
+	}
+	if assumeYes, err := strconv.ParseBool(os.Getenv(envVar)); err == nil && assumeYes {
+		return nil
+	}
+	if opts.NonInteractive {
+		return &nonInteractiveError{prompt: prompt, envVar: envVar}
+	}
+
+	ask := opts.Prompt
+	if ask == nil {
+		ask = surveyutils.GetYesInput
+	}
+	confirmed, err := ask(prompt)
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		return &declinedError{prompt: prompt}
+	}
+	return nil
+}
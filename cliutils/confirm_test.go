@@ -0,0 +1,67 @@
+package cliutils_test
+
+import (
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/solo-io/go-utils/cliutils"
+	"github.com/spf13/cobra"
+)
+
+var _ = Describe("ConfirmDestructive", func() {
+	var (
+		prompted bool
+		answer   bool
+	)
+
+	prompt := func(msg string) (bool, error) {
+		prompted = true
+		return answer, nil
+	}
+
+	BeforeEach(func() {
+		prompted = false
+		answer = false
+		os.Unsetenv(cliutils.DefaultAssumeYesEnvVar)
+	})
+
+	AfterEach(func() {
+		os.Unsetenv(cliutils.DefaultAssumeYesEnvVar)
+	})
+
+	It("prompts and honors the answer", func() {
+		answer = true
+		Expect(cliutils.ConfirmDestructive("uninstall everything?", cliutils.ConfirmOptions{Prompt: prompt})).To(Succeed())
+		Expect(prompted).To(BeTrue())
+
+		answer = false
+		err := cliutils.ConfirmDestructive("uninstall everything?", cliutils.ConfirmOptions{Prompt: prompt})
+		Expect(cliutils.IsDeclinedError(err)).To(BeTrue())
+	})
+
+	It("does not prompt when confirmed by a flag", func() {
+		cmd := &cobra.Command{Use: "uninstall"}
+		opts := &cliutils.ConfirmOptions{Prompt: prompt}
+		cliutils.AddConfirmFlags(cmd, opts)
+		Expect(cmd.ParseFlags([]string{"-y"})).To(Succeed())
+
+		Expect(cliutils.ConfirmDestructive("uninstall everything?", *opts)).To(Succeed())
+		Expect(prompted).To(BeFalse())
+		Expect(cliutils.ConfirmDestructive("uninstall everything?", cliutils.ConfirmOptions{Force: true, Prompt: prompt})).To(Succeed())
+		Expect(prompted).To(BeFalse())
+	})
+
+	It("does not prompt when confirmed by the env", func() {
+		os.Setenv(cliutils.DefaultAssumeYesEnvVar, "true")
+		Expect(cliutils.ConfirmDestructive("uninstall everything?", cliutils.ConfirmOptions{Prompt: prompt, NonInteractive: true})).To(Succeed())
+		Expect(prompted).To(BeFalse())
+	})
+
+	It("refuses when it can't prompt", func() {
+		err := cliutils.ConfirmDestructive("uninstall everything?", cliutils.ConfirmOptions{Prompt: prompt, NonInteractive: true})
+		Expect(cliutils.IsNonInteractiveError(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring(cliutils.DefaultAssumeYesEnvVar))
+		Expect(prompted).To(BeFalse())
+	})
+})
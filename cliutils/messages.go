@@ -0,0 +1,202 @@
+package cliutils
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+)
+
+// LocaleEnvVar selects the locale of CLI output, e.g. "de" or "pt-BR". If it isn't set, LC_ALL, LC_MESSAGES
+// and LANG are checked, and the default is DefaultLocale.
+const LocaleEnvVar = "CLI_LOCALE"
+
+// DefaultLocale is the locale of the messages passed to DefineMessage
+const DefaultLocale = "en"
+
+// MessageID identifies a user-facing string, e.g. "confirm.declined"
+type MessageID string
+
+// Catalog holds the translations of a locale, by message ID. Values are fmt format strings taking the same
+// arguments, in the same order, as the English message.
+type Catalog map[MessageID]string
+
+// Catalogs holds the messages of a CLI in every locale. Most CLIs use the package-level functions, which use
+// DefaultCatalogs; separate instances keep the translations of e.g. tests or plugins apart.
+type Catalogs struct {
+	lock     sync.RWMutex
+	catalogs map[string]Catalog
+}
+
+// DefaultCatalogs holds the messages used by the package-level functions
+var DefaultCatalogs = NewCatalogs()
+
+func NewCatalogs() *Catalogs {
+	return &Catalogs{catalogs: map[string]Catalog{DefaultLocale: {}}}
+}
+
+// DefineMessage adds the English text of a user-facing string to the default catalog and returns its ID, e.g.
+//
+//	var MsgInstallDone = cliutils.DefineMessage("install.done", "Installed %s in namespace %s")
+//	fmt.Println(cliutils.Localize(MsgInstallDone, product, namespace))
+//
+// Define messages in package-level vars, so that ExportCatalog can list all of them for translators.
+// Errors should stay in English, since callers may match on their text.
+func DefineMessage(id MessageID, english string) MessageID {
+	return DefaultCatalogs.DefineMessage(id, english)
+}
+
+// RegisterCatalog adds translations for locale, overriding earlier translations of the same messages
+func RegisterCatalog(locale string, catalog Catalog) {
+	DefaultCatalogs.RegisterCatalog(locale, catalog)
+}
+
+// LoadCatalogFile registers the translations for locale in a YAML file mapping message IDs to translations,
+// such as one written by ExportCatalog and filled in by a translator
+func LoadCatalogFile(locale, path string) error {
+	return DefaultCatalogs.LoadCatalogFile(locale, path)
+}
+
+// ExportCatalog writes every defined message as YAML with its translation for locale, or its English text if it
+// hasn't been translated yet, so translators can start from the output. Untranslated messages are listed in
+// a comment at the top.
+func ExportCatalog(w io.Writer, locale string) error {
+	return DefaultCatalogs.ExportCatalog(w, locale)
+}
+
+// Localize formats the message in the current locale, see LocaleEnvVar. It falls back to the language without
+// its region (e.g. "pt" for "pt-BR"), then to English, and to the ID itself for messages that were never defined.
+func Localize(id MessageID, args ...interface{}) string {
+	return DefaultCatalogs.Localize(id, args...)
+}
+
+// LocalizeIn is Localize for the given locale
+func LocalizeIn(locale string, id MessageID, args ...interface{}) string {
+	return DefaultCatalogs.LocalizeIn(locale, id, args...)
+}
+
+// DefineMessage is the package-level DefineMessage for these catalogs
+func (c *Catalogs) DefineMessage(id MessageID, english string) MessageID {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.catalogs[DefaultLocale][id] = english
+	return id
+}
+
+// RegisterCatalog is the package-level RegisterCatalog for these catalogs
+func (c *Catalogs) RegisterCatalog(locale string, catalog Catalog) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	locale = normalizeLocale(locale)
+	if c.catalogs[locale] == nil {
+		c.catalogs[locale] = Catalog{}
+	}
+	for id, text := range catalog {
+		c.catalogs[locale][id] = text
+	}
+}
+
+// LoadCatalogFile is the package-level LoadCatalogFile for these catalogs
+func (c *Catalogs) LoadCatalogFile(locale, path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var catalog Catalog
+	if err := yaml.Unmarshal(data, &catalog); err != nil {
+		return errors.Wrapf(err, "parsing message catalog %s", path)
+	}
+	c.RegisterCatalog(locale, catalog)
+	return nil
+}
+
+// ExportCatalog is the package-level ExportCatalog for these catalogs
+func (c *Catalogs) ExportCatalog(w io.Writer, locale string) error {
+	c.lock.RLock()
+	english := c.catalogs[DefaultLocale]
+	translated := c.catalogs[normalizeLocale(locale)]
+	export := Catalog{}
+	var missing []string
+	for id, text := range english {
+		if translation, ok := translated[id]; ok {
+			text = translation
+		} else {
+			missing = append(missing, string(id))
+		}
+		export[id] = text
+	}
+	c.lock.RUnlock()
+
+	sort.Strings(missing)
+	if len(missing) > 0 && normalizeLocale(locale) != DefaultLocale {
+		if _, err := fmt.Fprintf(w, "# untranslated: %s\n", strings.Join(missing, ", ")); err != nil {
+			return err
+		}
+	}
+	data, err := yaml.Marshal(export)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// Localize is the package-level Localize for these catalogs
+func (c *Catalogs) Localize(id MessageID, args ...interface{}) string {
+	return c.LocalizeIn(CurrentLocale(), id, args...)
+}
+
+// LocalizeIn is the package-level LocalizeIn for these catalogs
+func (c *Catalogs) LocalizeIn(locale string, id MessageID, args ...interface{}) string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	locale = normalizeLocale(locale)
+	candidates := []string{locale}
+	if idx := strings.Index(locale, "-"); idx > 0 {
+		candidates = append(candidates, locale[:idx])
+	}
+	candidates = append(candidates, DefaultLocale)
+	for _, candidate := range candidates {
+		if text, ok := c.catalogs[candidate][id]; ok {
+			if len(args) == 0 {
+				return text
+			}
+			return fmt.Sprintf(text, args...)
+		}
+	}
+	return string(id)
+}
+
+// CurrentLocale returns the locale selected by the environment, see LocaleEnvVar
+func CurrentLocale() string {
+	for _, envVar := range []string{LocaleEnvVar, "LC_ALL", "LC_MESSAGES", "LANG"} {
+		if locale := os.Getenv(envVar); locale != "" {
+			locale = normalizeLocale(locale)
+			// the POSIX locales don't select a language
+			if locale == "c" || locale == "posix" {
+				return DefaultLocale
+			}
+			return locale
+		}
+	}
+	return DefaultLocale
+}
+
+// normalizeLocale turns POSIX locales like "pt_BR.UTF-8" into BCP 47 tags like "pt-BR"
+func normalizeLocale(locale string) string {
+	if idx := strings.IndexAny(locale, ".@"); idx >= 0 {
+		locale = locale[:idx]
+	}
+	parts := strings.Split(strings.Replace(locale, "_", "-", -1), "-")
+	parts[0] = strings.ToLower(parts[0])
+	for i := 1; i < len(parts); i++ {
+		parts[i] = strings.ToUpper(parts[i])
+	}
+	return strings.Join(parts, "-")
+}
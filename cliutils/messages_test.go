@@ -0,0 +1,96 @@
+package cliutils_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/ghodss/yaml"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/solo-io/go-utils/cliutils"
+)
+
+var msgGreeting = cliutils.DefineMessage("test.greeting", "Hello, %s")
+
+var _ = Describe("Localized messages", func() {
+
+	var (
+		localeEnvVars = []string{cliutils.LocaleEnvVar, "LC_ALL", "LC_MESSAGES", "LANG"}
+		oldEnv        map[string]string
+		catalogs      *cliutils.Catalogs
+	)
+
+	BeforeEach(func() {
+		oldEnv = map[string]string{}
+		for _, envVar := range localeEnvVars {
+			if value, ok := os.LookupEnv(envVar); ok {
+				oldEnv[envVar] = value
+			}
+			os.Unsetenv(envVar)
+		}
+		catalogs = cliutils.NewCatalogs()
+		catalogs.DefineMessage(msgGreeting, "Hello, %s")
+		catalogs.RegisterCatalog("de", cliutils.Catalog{msgGreeting: "Hallo, %s"})
+	})
+
+	AfterEach(func() {
+		for _, envVar := range localeEnvVars {
+			os.Unsetenv(envVar)
+		}
+		for envVar, value := range oldEnv {
+			os.Setenv(envVar, value)
+		}
+	})
+
+	It("uses English by default", func() {
+		Expect(cliutils.CurrentLocale()).To(Equal(cliutils.DefaultLocale))
+		Expect(cliutils.Localize(msgGreeting, "world")).To(Equal("Hello, world"))
+		Expect(catalogs.Localize(msgGreeting, "world")).To(Equal("Hello, world"))
+	})
+
+	It("selects the locale from the environment", func() {
+		os.Setenv("LANG", "de_AT.UTF-8")
+		Expect(cliutils.CurrentLocale()).To(Equal("de-AT"))
+		// falls back from de-AT to de
+		Expect(catalogs.Localize(msgGreeting, "Welt")).To(Equal("Hallo, Welt"))
+
+		os.Setenv(cliutils.LocaleEnvVar, "fr")
+		Expect(catalogs.Localize(msgGreeting, "monde")).To(Equal("Hello, monde"))
+	})
+
+	It("keeps errors in English", func() {
+		os.Setenv(cliutils.LocaleEnvVar, "de")
+		err := cliutils.ConfirmDestructive("delete everything", cliutils.ConfirmOptions{
+			Prompt: func(string) (bool, error) { return false, nil },
+		})
+		Expect(err).To(MatchError("operation not confirmed: delete everything"))
+	})
+
+	It("exports the catalog for translators and loads their translations", func() {
+		out := &bytes.Buffer{}
+		Expect(catalogs.ExportCatalog(out, "es")).To(Succeed())
+		Expect(out.String()).To(HavePrefix("# untranslated: test.greeting\n"))
+		exported := cliutils.Catalog{}
+		Expect(yaml.Unmarshal(out.Bytes(), &exported)).To(Succeed())
+		Expect(exported).To(Equal(cliutils.Catalog{msgGreeting: "Hello, %s"}))
+
+		dir, err := ioutil.TempDir("", "catalog")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "es.yaml")
+		Expect(ioutil.WriteFile(path, []byte(`test.greeting: "Hola, %s"`), 0644)).To(Succeed())
+		Expect(catalogs.LoadCatalogFile("es", path)).To(Succeed())
+		Expect(catalogs.LocalizeIn("es_ES", msgGreeting, "mundo")).To(Equal("Hola, mundo"))
+		Expect(cliutils.LocalizeIn("es_ES", msgGreeting, "mundo")).To(Equal("Hello, mundo"))
+	})
+
+	It("exports the messages of the package", func() {
+		out := &bytes.Buffer{}
+		Expect(cliutils.ExportCatalog(out, cliutils.DefaultLocale)).To(Succeed())
+		exported := cliutils.Catalog{}
+		Expect(yaml.Unmarshal(out.Bytes(), &exported)).To(Succeed())
+		Expect(exported).To(HaveKeyWithValue(cliutils.MsgConfirmFlagUsage, "confirm destructive operations without prompting"))
+	})
+})
@@ -0,0 +1,185 @@
+package cliutils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+)
+
+// WizardValues holds the answers collected by the steps of a wizard, keyed by name
+type WizardValues map[string]string
+
+// WizardStep is a single step of an interactive flow.
+// Prompt collects input into the values, Validate checks it, and Run performs the step's action.
+// Any of the three may be nil.
+type WizardStep struct {
+	Name     string
+	Prompt   func(ctx context.Context, values WizardValues) error
+	Validate func(values WizardValues) error
+	Run      func(ctx context.Context, values WizardValues) error
+	// the names of the values collected by Prompt that are secret, e.g. passwords or tokens. They are masked
+	// in the summary.
+	Sensitive []string
+}
+
+const maskedWizardValue = "****"
+
+// Wizard runs a list of steps in order. If ProgressFile is set, the collected values and the names of
+// the completed steps are persisted there after every step, so that a failed run resumes at the step
+// that failed instead of starting over. Since the values may include secrets, the file is only readable
+// by the current user, and it is removed once the wizard completes.
+type Wizard struct {
+	Steps        []WizardStep
+	ProgressFile string
+	// if set, prompts and validations run but no step's Run function is called
+	DryRun bool
+	// where the summary is written, defaults to os.Stdout
+	Out io.Writer
+}
+
+type wizardProgress struct {
+	Completed []string     `json:"completed"`
+	Values    WizardValues `json:"values"`
+}
+
+// Run executes the wizard, returning the collected values
+func (w *Wizard) Run(ctx context.Context) (WizardValues, error) {
+	progress, err := w.loadProgress()
+	if err != nil {
+		return nil, err
+	}
+	completed := make(map[string]bool, len(progress.Completed))
+	for _, name := range progress.Completed {
+		completed[name] = true
+	}
+	// the steps completed by an earlier run come first in progress.Completed
+	resumed := len(progress.Completed)
+
+	for _, step := range w.Steps {
+		if completed[step.Name] {
+			continue
+		}
+		if step.Prompt != nil {
+			if err := step.Prompt(ctx, progress.Values); err != nil {
+				return progress.Values, errors.Wrapf(err, "step %s", step.Name)
+			}
+		}
+		if step.Validate != nil {
+			if err := step.Validate(progress.Values); err != nil {
+				return progress.Values, errors.Wrapf(err, "invalid input for step %s", step.Name)
+			}
+		}
+		if !w.DryRun && step.Run != nil {
+			if err := step.Run(ctx, progress.Values); err != nil {
+				// save the answers collected so far so they don't have to be entered again
+				if saveErr := w.saveProgress(progress); saveErr != nil {
+					return progress.Values, saveErr
+				}
+				return progress.Values, errors.Wrapf(err, "step %s failed", step.Name)
+			}
+		}
+		progress.Completed = append(progress.Completed, step.Name)
+		if !w.DryRun {
+			if err := w.saveProgress(progress); err != nil {
+				return progress.Values, err
+			}
+		}
+	}
+
+	if err := w.printSummary(progress, resumed); err != nil {
+		return progress.Values, err
+	}
+	if w.DryRun || w.ProgressFile == "" {
+		return progress.Values, nil
+	}
+	if err := os.Remove(w.ProgressFile); err != nil && !os.IsNotExist(err) {
+		return progress.Values, err
+	}
+	return progress.Values, nil
+}
+
+func (w *Wizard) loadProgress() (*wizardProgress, error) {
+	progress := &wizardProgress{Values: WizardValues{}}
+	if w.ProgressFile == "" {
+		return progress, nil
+	}
+	b, err := ioutil.ReadFile(w.ProgressFile)
+	if os.IsNotExist(err) {
+		return progress, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading wizard progress from %s", w.ProgressFile)
+	}
+	if err := yaml.Unmarshal(b, progress); err != nil {
+		return nil, errors.Wrapf(err, "parsing wizard progress from %s", w.ProgressFile)
+	}
+	if progress.Values == nil {
+		progress.Values = WizardValues{}
+	}
+	return progress, nil
+}
+
+func (w *Wizard) saveProgress(progress *wizardProgress) error {
+	if w.ProgressFile == "" {
+		return nil
+	}
+	b, err := yaml.Marshal(progress)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(w.ProgressFile, b, 0600); err != nil {
+		return errors.Wrapf(err, "saving wizard progress to %s", w.ProgressFile)
+	}
+	// WriteFile keeps the permissions of a file that already exists
+	if err := os.Chmod(w.ProgressFile, 0600); err != nil {
+		return errors.Wrapf(err, "saving wizard progress to %s", w.ProgressFile)
+	}
+	return nil
+}
+
+// printSummary lists the completed steps, the first resumed of which were completed by an earlier run, and the
+// collected values
+func (w *Wizard) printSummary(progress *wizardProgress, resumed int) error {
+	out := w.Out
+	if out == nil {
+		out = os.Stdout
+	}
+	for i, name := range progress.Completed {
+		verb := "Completed"
+		if i < resumed {
+			verb = "Already completed"
+		} else if w.DryRun {
+			verb = "Would run"
+		}
+		if _, err := fmt.Fprintf(out, "%s: %s\n", verb, name); err != nil {
+			return err
+		}
+	}
+	sensitive := map[string]bool{}
+	for _, step := range w.Steps {
+		for _, k := range step.Sensitive {
+			sensitive[k] = true
+		}
+	}
+	var keys []string
+	for k := range progress.Values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		value := progress.Values[k]
+		if sensitive[k] {
+			value = maskedWizardValue
+		}
+		if _, err := fmt.Fprintf(out, "  %s = %s\n", k, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package cliutils_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/solo-io/go-utils/cliutils"
+)
+
+var _ = Describe("Wizard", func() {
+	var (
+		ctx          = context.Background()
+		dir          string
+		progressFile string
+		ran          []string
+		failInstall  bool
+		out          *bytes.Buffer
+	)
+
+	steps := func() []cliutils.WizardStep {
+		return []cliutils.WizardStep{
+			{
+				Name: "namespace",
+				Prompt: func(ctx context.Context, values cliutils.WizardValues) error {
+					values["namespace"] = "gloo-system"
+					return nil
+				},
+				Validate: func(values cliutils.WizardValues) error {
+					if values["namespace"] == "" {
+						return errors.New("namespace is required")
+					}
+					return nil
+				},
+				Run: func(ctx context.Context, values cliutils.WizardValues) error {
+					ran = append(ran, "namespace")
+					return nil
+				},
+			},
+			{
+				Name: "install",
+				Prompt: func(ctx context.Context, values cliutils.WizardValues) error {
+					values["license-key"] = "secret-key"
+					return nil
+				},
+				Sensitive: []string{"license-key"},
+				Run: func(ctx context.Context, values cliutils.WizardValues) error {
+					ran = append(ran, "install")
+					if failInstall {
+						return errors.New("boom")
+					}
+					return nil
+				},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "wizard")
+		Expect(err).NotTo(HaveOccurred())
+		progressFile = filepath.Join(dir, "progress.yaml")
+		ran = nil
+		failInstall = false
+		out = &bytes.Buffer{}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("runs all steps and removes the progress file", func() {
+		w := &cliutils.Wizard{Steps: steps(), ProgressFile: progressFile, Out: out}
+		values, err := w.Run(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(values).To(HaveKeyWithValue("namespace", "gloo-system"))
+		Expect(ran).To(Equal([]string{"namespace", "install"}))
+		Expect(out.String()).To(ContainSubstring("Completed: install"))
+		_, err = os.Stat(progressFile)
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	It("resumes at the failed step", func() {
+		failInstall = true
+		w := &cliutils.Wizard{Steps: steps(), ProgressFile: progressFile, Out: out}
+		_, err := w.Run(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(progressFile).To(BeAnExistingFile())
+
+		failInstall = false
+		ran = nil
+		_, err = w.Run(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ran).To(Equal([]string{"install"}))
+	})
+
+	It("does not run steps in dry run mode", func() {
+		w := &cliutils.Wizard{Steps: steps(), ProgressFile: progressFile, DryRun: true, Out: out}
+		_, err := w.Run(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ran).To(BeEmpty())
+		Expect(out.String()).To(ContainSubstring("Would run: install"))
+		Expect(out.String()).To(ContainSubstring("namespace = gloo-system"))
+	})
+
+	It("doesn't say it would run the steps of an earlier run in dry run mode", func() {
+		failInstall = true
+		w := &cliutils.Wizard{Steps: steps(), ProgressFile: progressFile, Out: out}
+		_, err := w.Run(ctx)
+		Expect(err).To(HaveOccurred())
+
+		failInstall = false
+		ran = nil
+		out.Reset()
+		w.DryRun = true
+		_, err = w.Run(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ran).To(BeEmpty())
+		Expect(out.String()).To(ContainSubstring("Already completed: namespace\n"))
+		Expect(out.String()).To(ContainSubstring("Would run: install\n"))
+		Expect(out.String()).NotTo(ContainSubstring("Would run: namespace"))
+	})
+
+	It("masks sensitive values in the summary", func() {
+		w := &cliutils.Wizard{Steps: steps(), Out: out}
+		values, err := w.Run(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(values).To(HaveKeyWithValue("license-key", "secret-key"))
+		Expect(out.String()).To(ContainSubstring("license-key = ****"))
+		Expect(out.String()).NotTo(ContainSubstring("secret-key"))
+	})
+
+	It("only lets the current user read the progress file", func() {
+		Expect(ioutil.WriteFile(progressFile, []byte("completed: []\n"), 0644)).To(Succeed())
+		failInstall = true
+		w := &cliutils.Wizard{Steps: steps(), ProgressFile: progressFile, Out: out}
+		_, err := w.Run(ctx)
+		Expect(err).To(HaveOccurred())
+		info, err := os.Stat(progressFile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+	})
+})
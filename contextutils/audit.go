@@ -0,0 +1,97 @@
+package contextutils
+
+import (
+	"context"
+	"os"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// AuditActorEnvVar names who is running audited actions, e.g. the engineer or CI job using a shared cluster.
+// If it isn't set, the GitHub Actions actor or the current user is recorded.
+const AuditActorEnvVar = "AUDIT_ACTOR"
+
+type auditLoggerKey struct{}
+
+var (
+	fallbackAuditLoggerLock sync.RWMutex
+	// used by Audit when there is no audit logger in the context, e.g. for helpers that don't take a context
+	fallbackAuditLogger *zap.SugaredLogger
+)
+
+// NewAuditLogger returns a logger for audit events that writes JSON lines to the given paths, e.g. a file
+// collected with the CI artifacts, or "stderr". Unlike other loggers, it ignores SetLogLevel and never samples,
+// so no audit event is dropped.
+func NewAuditLogger(paths ...string) (*zap.SugaredLogger, error) {
+	config := zap.NewProductionConfig()
+	config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	config.Level = zap.NewAtomicLevelAt(zap.InfoLevel)
+	config.Sampling = nil
+	config.DisableStacktrace = true
+	config.OutputPaths = paths
+	logger, err := config.Build()
+	if err != nil {
+		return nil, err
+	}
+	return logger.Named("audit").Sugar(), nil
+}
+
+// SetAuditLogger sets the audit logger used when there is none in the context, e.g. in a suite's BeforeSuite
+func SetAuditLogger(logger *zap.SugaredLogger) {
+	fallbackAuditLoggerLock.Lock()
+	defer fallbackAuditLoggerLock.Unlock()
+	fallbackAuditLogger = logger
+}
+
+// WithAuditLogger returns a copy of ctx in which Audit writes to logger, keeping audit events out of the
+// regular logs
+func WithAuditLogger(ctx context.Context, logger *zap.SugaredLogger) context.Context {
+	return context.WithValue(ctx, auditLoggerKey{}, logger)
+}
+
+// AuditLoggerFrom returns the audit logger stored in ctx, or the one set with SetAuditLogger. If neither is set,
+// audit events go to the regular logger, named "audit".
+func AuditLoggerFrom(ctx context.Context) *zap.SugaredLogger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(auditLoggerKey{}).(*zap.SugaredLogger); ok {
+			return logger
+		}
+	}
+	fallbackAuditLoggerLock.RLock()
+	logger := fallbackAuditLogger
+	fallbackAuditLoggerLock.RUnlock()
+	if logger != nil {
+		return logger
+	}
+	return fromContext(ctx).Named("audit")
+}
+
+// Audit records that a destructive action, such as deleting a namespace or uninstalling a release, was taken
+// on target, along with who took it and whether it succeeded; the time is added by the logger. Helpers should
+// call it after the action, passing its error, e.g.
+//
+//	err := deleteNamespace(ctx, name)
+//	contextutils.Audit(ctx, "delete namespace", name, err, "cluster", cluster)
+func Audit(ctx context.Context, action, target string, err error, meta ...interface{}) {
+	fields := append([]interface{}{
+		"actor", auditActor(),
+		"action", action,
+		"target", target,
+	}, meta...)
+	if err != nil {
+		AuditLoggerFrom(ctx).Errorw("Audit", append(fields, "outcome", "failure", zap.Error(err))...)
+		return
+	}
+	AuditLoggerFrom(ctx).Infow("Audit", append(fields, "outcome", "success")...)
+}
+
+func auditActor() string {
+	for _, envVar := range []string{AuditActorEnvVar, "GITHUB_ACTOR", "USER"} {
+		if actor := os.Getenv(envVar); actor != "" {
+			return actor
+		}
+	}
+	return "unknown"
+}
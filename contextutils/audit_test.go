@@ -0,0 +1,96 @@
+package contextutils_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/solo-io/go-utils/contextutils"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("Audit", func() {
+	var (
+		dir     string
+		logPath string
+		ctx     context.Context
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "audit")
+		Expect(err).NotTo(HaveOccurred())
+		logPath = filepath.Join(dir, "audit.log")
+		logger, err := contextutils.NewAuditLogger(logPath)
+		Expect(err).NotTo(HaveOccurred())
+		ctx = contextutils.WithAuditLogger(context.Background(), logger)
+		os.Setenv(contextutils.AuditActorEnvVar, "ci-job-123")
+	})
+
+	AfterEach(func() {
+		os.Unsetenv(contextutils.AuditActorEnvVar)
+		contextutils.SetAuditLogger(nil)
+		os.RemoveAll(dir)
+	})
+
+	readEvents := func() []map[string]interface{} {
+		contents, err := ioutil.ReadFile(logPath)
+		Expect(err).NotTo(HaveOccurred())
+		var events []map[string]interface{}
+		for _, line := range strings.Split(strings.TrimSpace(string(contents)), "\n") {
+			event := map[string]interface{}{}
+			Expect(json.Unmarshal([]byte(line), &event)).To(Succeed())
+			events = append(events, event)
+		}
+		return events
+	}
+
+	It("records who took which action on what, and the outcome", func() {
+		contextutils.Audit(ctx, "delete namespace", "gloo-system", nil, "cluster", "shared-1")
+		contextutils.Audit(ctx, "uninstall release", "gloo", errors.New("release not found"))
+
+		events := readEvents()
+		Expect(events).To(HaveLen(2))
+		Expect(events[0]).To(HaveKeyWithValue("actor", "ci-job-123"))
+		Expect(events[0]).To(HaveKeyWithValue("action", "delete namespace"))
+		Expect(events[0]).To(HaveKeyWithValue("target", "gloo-system"))
+		Expect(events[0]).To(HaveKeyWithValue("cluster", "shared-1"))
+		Expect(events[0]).To(HaveKeyWithValue("outcome", "success"))
+		Expect(events[0]).To(HaveKey("ts"))
+		Expect(events[1]).To(HaveKeyWithValue("outcome", "failure"))
+		Expect(events[1]).To(HaveKeyWithValue("error", "release not found"))
+	})
+
+	It("isn't affected by the log level", func() {
+		contextutils.SetLogLevel(zap.ErrorLevel)
+		defer contextutils.SetLogLevel(zap.InfoLevel)
+		contextutils.Audit(ctx, "delete crd", "upstreams.gloo.solo.io", nil)
+		Expect(readEvents()).To(HaveLen(1))
+	})
+
+	It("uses the audit logger set for helpers without a context", func() {
+		contextutils.SetAuditLogger(contextutils.AuditLoggerFrom(ctx))
+		contextutils.Audit(context.Background(), "delete namespace", "test-ns", nil)
+		Expect(readEvents()).To(HaveLen(1))
+	})
+	It("can set the audit logger while audits are happening", func() {
+		logger := contextutils.AuditLoggerFrom(ctx)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 50; i++ {
+				contextutils.SetAuditLogger(logger)
+			}
+		}()
+		for i := 0; i < 50; i++ {
+			contextutils.Audit(context.Background(), "delete namespace", "test-ns", nil)
+		}
+		<-done
+	})
+})
@@ -0,0 +1,293 @@
+package contextutils
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// BufferedCore is a zapcore.Core that holds on to debug logs instead of writing them. The most recent ones are
+// kept in a ring buffer and written when an error is logged or Flush is called, e.g. when a test fails, so that
+// runs that go well stay quiet while failures come with the debug logs leading up to them.
+// Logs at info level and above are written asynchronously, in order, by a goroutine started with the core, so that
+// logging doesn't wait on slow writers; Sync and Flush wait for the queued logs to be written, and Close stops the
+// goroutine. Fields that are only read when encoded, such as objects and Stringers, are captured when logging, so
+// that logs written later show the state at the time.
+type BufferedCore struct {
+	zapcore.LevelEnabler
+	// the core entries are written to, including the fields added with With
+	inner  zapcore.Core
+	buffer *logRingBuffer
+	writer *asyncWriter
+}
+
+// asyncQueueSize is how many writes can be queued before logging blocks until the writer catches up
+const asyncQueueSize = 1024
+
+// asyncWriter runs queued writes one at a time, keeping the first error until the next wait. Once closed, writes
+// run right away on the caller's goroutine.
+type asyncWriter struct {
+	// held for writing to close the queue, and for reading to send to it
+	state   sync.RWMutex
+	closed  bool
+	queue   chan asyncWrite
+	stopped chan struct{}
+	lock    sync.Mutex
+	err     error
+}
+
+type asyncWrite struct {
+	write func() error
+	// closed once this and all earlier writes are done
+	done chan struct{}
+}
+
+type bufferedEntry struct {
+	core   zapcore.Core
+	entry  zapcore.Entry
+	fields []zapcore.Field
+}
+
+type logRingBuffer struct {
+	lock    sync.Mutex
+	entries []bufferedEntry
+	// the index of the oldest entry once the buffer is full
+	next    int
+	dropped int
+}
+
+// NewBufferedCore returns a core that keeps the last size debug logs and writes everything else to inner
+func NewBufferedCore(inner zapcore.Core, size int) *BufferedCore {
+	if size < 1 {
+		size = 1
+	}
+	return &BufferedCore{
+		LevelEnabler: zapcore.DebugLevel,
+		inner:        inner,
+		buffer:       &logRingBuffer{entries: make([]bufferedEntry, 0, size)},
+		writer:       newAsyncWriter(),
+	}
+}
+
+// BufferFallbackLogger replaces the fallback logger with one that buffers debug logs, see BufferedCore,
+// returning the core so that the caller can Flush it
+func BufferFallbackLogger(size int) *BufferedCore {
+	fallbackLoggerLock.Lock()
+	defer fallbackLoggerLock.Unlock()
+	core := NewBufferedCore(fallbackLogger.Desugar().Core(), size)
+	fallbackLogger = zap.New(core).Sugar()
+	return core
+}
+
+func (c *BufferedCore) With(fields []zapcore.Field) zapcore.Core {
+	return &BufferedCore{
+		LevelEnabler: c.LevelEnabler,
+		inner:        c.inner.With(fields),
+		buffer:       c.buffer,
+		writer:       c.writer,
+	}
+}
+
+func (c *BufferedCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	// debug logs are buffered whatever the level of inner is, so that they are there when they're flushed
+	if !c.Enabled(entry.Level) || (entry.Level >= zapcore.InfoLevel && !c.inner.Enabled(entry.Level)) {
+		return checked
+	}
+	return checked.AddCore(entry, c)
+}
+
+func (c *BufferedCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	fields = snapshotFields(fields)
+	if entry.Level < zapcore.InfoLevel {
+		c.buffer.add(bufferedEntry{core: c.inner, entry: entry, fields: fields})
+		return nil
+	}
+	inner := c.inner
+	if entry.Level < zapcore.ErrorLevel {
+		c.writer.enqueue(func() error {
+			return inner.Write(entry, fields)
+		})
+		return nil
+	}
+	entries, dropped := c.buffer.drain()
+	c.writer.enqueue(func() error {
+		if err := c.writeBuffered(entries, dropped); err != nil {
+			return err
+		}
+		return inner.Write(entry, fields)
+	})
+	if entry.Level > zapcore.ErrorLevel {
+		// the process may be about to panic or exit, so don't leave the logs in the queue
+		return c.writer.wait()
+	}
+	return nil
+}
+
+// Sync waits for the queued logs to be written and syncs the wrapped core, returning the first error writing
+// the logs since the last Sync or Flush
+func (c *BufferedCore) Sync() error {
+	if err := c.writer.wait(); err != nil {
+		return err
+	}
+	return c.inner.Sync()
+}
+
+// Flush writes the buffered debug logs, oldest first, preceded by a warning if older ones had to be dropped.
+// It returns once they, and the logs queued before them, are written.
+func (c *BufferedCore) Flush() error {
+	entries, dropped := c.buffer.drain()
+	c.writer.enqueue(func() error {
+		return c.writeBuffered(entries, dropped)
+	})
+	return c.writer.wait()
+}
+
+func (c *BufferedCore) writeBuffered(entries []bufferedEntry, dropped int) error {
+	if dropped > 0 {
+		warning := zapcore.Entry{Level: zapcore.WarnLevel, Time: entries[0].entry.Time, Message: "Older debug logs were dropped"}
+		if err := c.inner.Write(warning, []zapcore.Field{zap.Int("dropped", dropped)}); err != nil {
+			return err
+		}
+	}
+	for _, buffered := range entries {
+		if err := buffered.core.Write(buffered.entry, buffered.fields); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Discard drops the buffered debug logs, e.g. after a test passed
+func (c *BufferedCore) Discard() {
+	c.buffer.drain()
+}
+
+// Close waits for the queued logs to be written and stops the goroutine writing them, returning the first error
+// writing them since the last Sync or Flush. Logs at info level and above are written synchronously from then on,
+// and debug logs are still buffered until they are flushed.
+func (c *BufferedCore) Close() error {
+	return c.writer.close()
+}
+
+// snapshotFields copies fields, replacing the values that are only read when they are encoded with what they
+// encode to now
+func snapshotFields(fields []zapcore.Field) []zapcore.Field {
+	snapshot := make([]zapcore.Field, len(fields))
+	for i, field := range fields {
+		switch field.Type {
+		case zapcore.ArrayMarshalerType, zapcore.ObjectMarshalerType, zapcore.StringerType:
+			encoder := zapcore.NewMapObjectEncoder()
+			field.AddTo(encoder)
+			if value, ok := encoder.Fields[field.Key]; ok {
+				field = zap.Any(field.Key, value)
+			}
+		case zapcore.ReflectType:
+			// a round trip through JSON copies the value, which is encoded the same way
+			if data, err := json.Marshal(field.Interface); err == nil {
+				var value interface{}
+				decoder := json.NewDecoder(bytes.NewReader(data))
+				decoder.UseNumber()
+				if err := decoder.Decode(&value); err == nil {
+					field = zap.Any(field.Key, value)
+				}
+			}
+		}
+		snapshot[i] = field
+	}
+	return snapshot
+}
+
+func (b *logRingBuffer) add(entry bufferedEntry) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if len(b.entries) < cap(b.entries) {
+		b.entries = append(b.entries, entry)
+		return
+	}
+	b.entries[b.next] = entry
+	b.next = (b.next + 1) % len(b.entries)
+	b.dropped++
+}
+
+func (b *logRingBuffer) drain() ([]bufferedEntry, int) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	entries := append(append([]bufferedEntry{}, b.entries[b.next:]...), b.entries[:b.next]...)
+	dropped := b.dropped
+	b.entries = b.entries[:0]
+	b.next, b.dropped = 0, 0
+	return entries, dropped
+}
+
+func newAsyncWriter() *asyncWriter {
+	w := &asyncWriter{queue: make(chan asyncWrite, asyncQueueSize), stopped: make(chan struct{})}
+	go w.run()
+	return w
+}
+
+func (w *asyncWriter) run() {
+	defer close(w.stopped)
+	for queued := range w.queue {
+		if queued.write != nil {
+			w.record(queued.write())
+		}
+		if queued.done != nil {
+			close(queued.done)
+		}
+	}
+}
+
+func (w *asyncWriter) record(err error) {
+	if err == nil {
+		return
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.err == nil {
+		w.err = err
+	}
+}
+
+func (w *asyncWriter) enqueue(write func() error) {
+	w.state.RLock()
+	defer w.state.RUnlock()
+	if w.closed {
+		w.record(write())
+		return
+	}
+	w.queue <- asyncWrite{write: write}
+}
+
+// wait returns once everything queued so far is written, with the first error since the last wait
+func (w *asyncWriter) wait() error {
+	w.state.RLock()
+	if !w.closed {
+		done := make(chan struct{})
+		w.queue <- asyncWrite{done: done}
+		<-done
+	}
+	w.state.RUnlock()
+	return w.takeErr()
+}
+
+func (w *asyncWriter) close() error {
+	w.state.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.queue)
+		<-w.stopped
+	}
+	w.state.Unlock()
+	return w.takeErr()
+}
+
+func (w *asyncWriter) takeErr() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	err := w.err
+	w.err = nil
+	return err
+}
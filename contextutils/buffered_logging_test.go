@@ -0,0 +1,175 @@
+package contextutils_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"runtime"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/solo-io/go-utils/contextutils"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var _ = Describe("BufferedCore", func() {
+	var (
+		out    *bytes.Buffer
+		core   *contextutils.BufferedCore
+		logger *zap.SugaredLogger
+	)
+
+	BeforeEach(func() {
+		out = &bytes.Buffer{}
+		encoder := zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "msg", LevelKey: "level", EncodeLevel: zapcore.LowercaseLevelEncoder})
+		core = contextutils.NewBufferedCore(zapcore.NewCore(encoder, zapcore.AddSync(out), zapcore.InfoLevel), 3)
+		logger = zap.New(core).Sugar()
+	})
+
+	AfterEach(func() {
+		Expect(core.Close()).To(Succeed())
+	})
+
+	messages := func() []string {
+		Expect(core.Sync()).To(Succeed())
+		var messages []string
+		for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+			if line == "" {
+				continue
+			}
+			entry := map[string]interface{}{}
+			Expect(json.Unmarshal([]byte(line), &entry)).To(Succeed())
+			messages = append(messages, entry["msg"].(string))
+		}
+		return messages
+	}
+
+	It("writes info logs right away and holds on to debug logs", func() {
+		logger.Debug("debug 1")
+		logger.Info("info 1")
+		Expect(messages()).To(Equal([]string{"info 1"}))
+
+		Expect(core.Flush()).To(Succeed())
+		Expect(messages()).To(Equal([]string{"info 1", "debug 1"}))
+	})
+
+	It("flushes debug logs before errors", func() {
+		logger.With("component", "installer").Debug("debug 1")
+		logger.Debug("debug 2")
+		logger.Error("error 1")
+		Expect(messages()).To(Equal([]string{"debug 1", "debug 2", "error 1"}))
+		Expect(out.String()).To(ContainSubstring(`"component":"installer"`))
+
+		logger.Error("error 2")
+		Expect(messages()).To(Equal([]string{"debug 1", "debug 2", "error 1", "error 2"}))
+	})
+
+	It("keeps only the most recent debug logs", func() {
+		for _, message := range []string{"debug 1", "debug 2", "debug 3", "debug 4", "debug 5"} {
+			logger.Debug(message)
+		}
+		Expect(core.Flush()).To(Succeed())
+		Expect(messages()).To(Equal([]string{"Older debug logs were dropped", "debug 3", "debug 4", "debug 5"}))
+		Expect(out.String()).To(ContainSubstring(`"dropped":2`))
+	})
+
+	It("discards debug logs", func() {
+		logger.Debug("debug 1")
+		core.Discard()
+		Expect(core.Flush()).To(Succeed())
+		Expect(out.String()).To(BeEmpty())
+	})
+
+	It("respects the level of the wrapped core above debug", func() {
+		encoder := zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "msg"})
+		core = contextutils.NewBufferedCore(zapcore.NewCore(encoder, zapcore.AddSync(out), zapcore.WarnLevel), 3)
+		logger = zap.New(core).Sugar()
+		logger.Info("info 1")
+		logger.Debug("debug 1")
+		logger.Warn("warn 1")
+		Expect(core.Flush()).To(Succeed())
+		Expect(messages()).To(Equal([]string{"warn 1", "debug 1"}))
+	})
+
+	It("logs fields as they were when logging", func() {
+		state := &loggedState{Phase: "pending"}
+		logger.Desugar().Debug("debug 1", zap.Any("state", state), zap.Stringer("phase", phaseStringer{state}))
+		logger.Desugar().Info("info 1", zap.Any("state", state), zap.Stringer("phase", phaseStringer{state}))
+		state.Phase = "ready"
+		Expect(core.Flush()).To(Succeed())
+		Expect(out.String()).NotTo(ContainSubstring("ready"))
+		Expect(strings.Count(out.String(), `"state":{"Phase":"pending"},"phase":"pending"`)).To(Equal(2))
+	})
+
+	It("stops writing asynchronously once closed", func() {
+		goroutines := runtime.NumGoroutine()
+		closed := contextutils.NewBufferedCore(zapcore.NewNopCore(), 3)
+		Expect(runtime.NumGoroutine()).To(Equal(goroutines + 1))
+		Expect(closed.Close()).To(Succeed())
+		Eventually(runtime.NumGoroutine).Should(Equal(goroutines))
+
+		Expect(core.Close()).To(Succeed())
+		logger.Debug("debug 1")
+		logger.Info("info 1")
+		Expect(out.String()).To(ContainSubstring("info 1"))
+		Expect(core.Flush()).To(Succeed())
+		Expect(messages()).To(Equal([]string{"info 1", "debug 1"}))
+	})
+
+	It("doesn't wait for logs to be written", func() {
+		blocked := &blockingCore{Core: zapcore.NewNopCore(), release: make(chan struct{})}
+		core = contextutils.NewBufferedCore(blocked, 3)
+		logger = zap.New(core).Sugar()
+
+		logged := make(chan struct{})
+		go func() {
+			defer close(logged)
+			logger.Info("info 1")
+			logger.Error("error 1")
+		}()
+		Eventually(logged).Should(BeClosed())
+
+		close(blocked.release)
+		Expect(core.Sync()).To(Succeed())
+	})
+
+	It("returns write errors from Sync", func() {
+		core = contextutils.NewBufferedCore(&blockingCore{Core: zapcore.NewNopCore(), err: errors.New("disk full")}, 3)
+		logger = zap.New(core).Sugar()
+		logger.Info("info 1")
+		Expect(core.Sync()).To(MatchError("disk full"))
+		Expect(core.Sync()).To(Succeed())
+	})
+})
+
+type loggedState struct {
+	Phase string
+}
+
+type phaseStringer struct {
+	state *loggedState
+}
+
+func (s phaseStringer) String() string {
+	return s.state.Phase
+}
+
+// blockingCore accepts every entry, and writes them once release is closed, failing with err if set
+type blockingCore struct {
+	zapcore.Core
+	release chan struct{}
+	err     error
+}
+
+func (c *blockingCore) Enabled(zapcore.Level) bool {
+	return true
+}
+
+func (c *blockingCore) Write(zapcore.Entry, []zapcore.Field) error {
+	if c.release != nil {
+		<-c.release
+	}
+	return c.err
+}
@@ -19,6 +19,7 @@ package contextutils
 
 import (
 	"context"
+	"sync"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -27,6 +28,7 @@ import (
 type loggerKey struct{}
 
 var (
+	fallbackLoggerLock sync.RWMutex
 	// This logger is used when there is no logger attached to the context.
 	// Rather than returning nil and causing a panic, we will use the fallback
 	// logger.
@@ -56,6 +58,8 @@ func init() {
 }
 
 func SetFallbackLogger(logger *zap.SugaredLogger) {
+	fallbackLoggerLock.Lock()
+	defer fallbackLoggerLock.Unlock()
 	fallbackLogger = logger
 }
 
@@ -74,6 +78,8 @@ func fromContext(ctx context.Context) *zap.SugaredLogger {
 			return logger
 		}
 	}
+	fallbackLoggerLock.RLock()
+	defer fallbackLoggerLock.RUnlock()
 	return fallbackLogger
 }
 
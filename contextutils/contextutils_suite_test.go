@@ -0,0 +1,13 @@
+package contextutils_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestContextutils(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Contextutils Suite")
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"context"
+	"flag"
+
+	"github.com/solo-io/go-utils/docker"
+	"github.com/solo-io/go-utils/log"
+)
+
+// Removes containers, images, volumes and networks left behind by test fixtures.
+// Intended to be run periodically on CI hosts, e.g. `go run docker/cmd/janitor/main.go -ttl 12h`
+func main() {
+	opts := docker.JanitorOptions{}
+	flag.StringVar(&opts.Label, "label", docker.DefaultTestLabel, "only remove resources with this label (key or key=value)")
+	flag.DurationVar(&opts.TTL, "ttl", docker.DefaultJanitorTTL, "only remove resources older than this")
+	flag.BoolVar(&opts.DryRun, "dry-run", false, "print what would be removed without removing it")
+	flag.Parse()
+
+	removed, err := docker.CleanupTestResources(context.Background(), opts)
+	for kind, ids := range removed {
+		log.Printf("%s: %d removed", kind, len(ids))
+	}
+	if err != nil {
+		log.Fatalf("unable to clean up all test resources: %v", err)
+	}
+}
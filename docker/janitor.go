@@ -0,0 +1,172 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+	"github.com/solo-io/go-utils/contextutils"
+)
+
+const (
+	// DefaultTestLabel is the label test fixtures should set on every container, image, volume
+	// and network they create so the janitor can find them later
+	DefaultTestLabel = "io.solo.test-fixture"
+	// DefaultJanitorTTL is how old a labeled resource has to be before the janitor removes it
+	DefaultJanitorTTL = 24 * time.Hour
+)
+
+// ResourceKind is a kind of docker object the janitor knows how to clean up
+type ResourceKind string
+
+const (
+	Container ResourceKind = "container"
+	Image     ResourceKind = "image"
+	Volume    ResourceKind = "volume"
+	Network   ResourceKind = "network"
+)
+
+// containers are removed first so that the images, volumes and networks they hold are free to be removed
+var janitorKinds = []ResourceKind{Container, Image, Volume, Network}
+
+type JanitorOptions struct {
+	// only resources carrying this label (as "key" or "key=value") are considered.
+	// defaults to DefaultTestLabel
+	Label string
+	// resources created less than TTL ago are left alone.
+	// defaults to DefaultJanitorTTL
+	TTL time.Duration
+	// if set, log what would be removed without removing anything
+	DryRun bool
+}
+
+// Removed lists the IDs of the resources removed (or, in a dry run, that would be removed) by the janitor
+type Removed map[ResourceKind][]string
+
+// CleanupTestResources removes every container, image, volume and network labeled with opts.Label
+// that is older than opts.TTL. It is safe to call at the start of a test suite; errors removing
+// individual resources are aggregated and returned after all resources have been attempted.
+func CleanupTestResources(ctx context.Context, opts JanitorOptions) (Removed, error) {
+	logger := contextutils.LoggerFrom(ctx)
+	if opts.Label == "" {
+		opts.Label = DefaultTestLabel
+	}
+	if opts.TTL == 0 {
+		opts.TTL = DefaultJanitorTTL
+	}
+	cutoff := time.Now().Add(-opts.TTL)
+
+	removed := Removed{}
+	var result *multierror.Error
+	for _, kind := range janitorKinds {
+		ids, err := listLabeled(kind, opts.Label)
+		if err != nil {
+			result = multierror.Append(result, err)
+			continue
+		}
+		for _, id := range ids {
+			created, err := createdAt(kind, id)
+			if err != nil {
+				result = multierror.Append(result, err)
+				continue
+			}
+			if created.After(cutoff) {
+				continue
+			}
+			if opts.DryRun {
+				logger.Infof("Would remove %s %s created at %s", kind, id, created)
+				removed[kind] = append(removed[kind], id)
+				continue
+			}
+			logger.Infof("Removing %s %s created at %s", kind, id, created)
+			_, err = output(removeArgs(kind, id)...)
+			contextutils.Audit(ctx, "remove docker "+string(kind), id, err, "label", opts.Label)
+			if err != nil {
+				result = multierror.Append(result, err)
+				continue
+			}
+			removed[kind] = append(removed[kind], id)
+		}
+	}
+	return removed, result.ErrorOrNil()
+}
+
+func listLabeled(kind ResourceKind, label string) ([]string, error) {
+	var args []string
+	switch kind {
+	case Container:
+		args = []string{"container", "ls", "--all", "--quiet", "--no-trunc"}
+	case Image:
+		args = []string{"image", "ls", "--all", "--quiet", "--no-trunc"}
+	default:
+		args = []string{string(kind), "ls", "--quiet"}
+	}
+	args = append(args, "--filter", "label="+label)
+	out, err := output(args...)
+	if err != nil {
+		return nil, err
+	}
+	return uniqueFields(out), nil
+}
+
+func createdAt(kind ResourceKind, id string) (time.Time, error) {
+	// volumes are the odd one out and call the field CreatedAt. Networks store it as a time.Time, which the
+	// template would render in Go's default format, so the json function is used to get RFC 3339 for every kind.
+	format := "{{json .Created}}"
+	if kind == Volume {
+		format = "{{json .CreatedAt}}"
+	}
+	out, err := output(string(kind), "inspect", "--format", format, id)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var createdString string
+	if err := json.Unmarshal([]byte(strings.TrimSpace(out)), &createdString); err != nil {
+		return time.Time{}, errors.Wrapf(err, "parsing creation time of %s %s", kind, id)
+	}
+	created, err := time.Parse(time.RFC3339Nano, createdString)
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "parsing creation time of %s %s", kind, id)
+	}
+	return created, nil
+}
+
+func removeArgs(kind ResourceKind, id string) []string {
+	switch kind {
+	case Container:
+		return []string{"container", "rm", "--force", "--volumes", id}
+	case Image:
+		return []string{"image", "rm", "--force", id}
+	default:
+		return []string{string(kind), "rm", id}
+	}
+}
+
+// uniqueFields splits docker's one-ID-per-line output, dropping duplicates (image ls repeats an ID once per tag)
+func uniqueFields(out string) []string {
+	var ids []string
+	seen := map[string]bool{}
+	for _, id := range strings.Fields(out) {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func output(args ...string) (string, error) {
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd := Command(args...)
+	cmd.stdout = stdout
+	cmd.stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return "", errors.Wrapf(err, "docker %s failed: %s", strings.Join(args, " "), stderr.String())
+	}
+	return stdout.String(), nil
+}
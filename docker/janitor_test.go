@@ -0,0 +1,132 @@
+package docker_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/solo-io/go-utils/docker"
+)
+
+// answers the commands the janitor runs, and records them in $FAKE_DOCKER_LOG
+const fakeDocker = `#!/bin/sh
+echo "$*" >> "$FAKE_DOCKER_LOG"
+case "$*" in
+"container ls --all --quiet --no-trunc --filter label=$FAKE_DOCKER_LABEL")
+	echo old-container; echo new-container ;;
+"image ls --all --quiet --no-trunc --filter label=$FAKE_DOCKER_LABEL")
+	# listed once per tag
+	echo sha256:old-image; echo sha256:old-image ;;
+"volume ls --quiet --filter label=$FAKE_DOCKER_LABEL")
+	echo old-volume; echo broken-volume ;;
+"network ls --quiet --filter label=$FAKE_DOCKER_LABEL")
+	echo old-network ;;
+"container inspect --format {{json .Created}} old-container")
+	echo '"2020-01-01T00:00:00.123456789Z"' ;;
+"container inspect --format {{json .Created}} new-container")
+	echo "\"$FAKE_DOCKER_NOW\"" ;;
+"image inspect --format {{json .Created}} sha256:old-image")
+	echo '"2020-01-01T00:00:00Z"' ;;
+"volume inspect --format {{json .CreatedAt}} old-volume")
+	echo '"2020-01-01T00:00:00Z"' ;;
+"volume inspect --format {{json .CreatedAt}} broken-volume")
+	echo '2020-01-01 00:00:00 +0000 UTC' ;;
+"network inspect --format {{json .Created}} old-network")
+	echo '"2020-01-01T01:00:00.5+01:00"' ;;
+"container rm --force --volumes "*|"image rm --force "*|"volume rm "*|"network rm "*)
+	;;
+*)
+	echo "unexpected command: $*" >&2; exit 1 ;;
+esac
+`
+
+var _ = Describe("CleanupTestResources", func() {
+	var (
+		ctx     = context.Background()
+		dir     string
+		logPath string
+		oldPath string
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "janitor")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ioutil.WriteFile(filepath.Join(dir, "docker"), []byte(fakeDocker), 0755)).To(Succeed())
+		logPath = filepath.Join(dir, "commands.log")
+		oldPath = os.Getenv("PATH")
+		os.Setenv("PATH", dir+string(os.PathListSeparator)+oldPath)
+		os.Setenv("FAKE_DOCKER_LOG", logPath)
+		os.Setenv("FAKE_DOCKER_LABEL", docker.DefaultTestLabel)
+		os.Setenv("FAKE_DOCKER_NOW", time.Now().UTC().Format(time.RFC3339Nano))
+	})
+
+	AfterEach(func() {
+		os.Setenv("PATH", oldPath)
+		for _, envVar := range []string{"FAKE_DOCKER_LOG", "FAKE_DOCKER_LABEL", "FAKE_DOCKER_NOW"} {
+			os.Unsetenv(envVar)
+		}
+		os.RemoveAll(dir)
+	})
+
+	commands := func() []string {
+		contents, err := ioutil.ReadFile(logPath)
+		Expect(err).NotTo(HaveOccurred())
+		return strings.Split(strings.TrimSpace(string(contents)), "\n")
+	}
+
+	removals := func() []string {
+		var removals []string
+		for _, command := range commands() {
+			if strings.Contains(command, " rm ") {
+				removals = append(removals, command)
+			}
+		}
+		return removals
+	}
+
+	It("removes labeled resources older than the TTL", func() {
+		removed, err := docker.CleanupTestResources(ctx, docker.JanitorOptions{})
+		// the creation time of broken-volume can't be parsed, which doesn't stop the other resources from being removed
+		Expect(err).To(MatchError(ContainSubstring("parsing creation time of volume broken-volume")))
+		Expect(removed).To(Equal(docker.Removed{
+			docker.Container: {"old-container"},
+			docker.Image:     {"sha256:old-image"},
+			docker.Volume:    {"old-volume"},
+			docker.Network:   {"old-network"},
+		}))
+		Expect(removals()).To(Equal([]string{
+			"container rm --force --volumes old-container",
+			"image rm --force sha256:old-image",
+			"volume rm old-volume",
+			"network rm old-network",
+		}))
+	})
+
+	It("removes nothing in a dry run", func() {
+		removed, err := docker.CleanupTestResources(ctx, docker.JanitorOptions{DryRun: true})
+		Expect(err).To(HaveOccurred())
+		Expect(removed[docker.Network]).To(Equal([]string{"old-network"}))
+		Expect(removals()).To(BeEmpty())
+	})
+
+	It("leaves resources younger than the TTL alone", func() {
+		removed, err := docker.CleanupTestResources(ctx, docker.JanitorOptions{TTL: 100 * 365 * 24 * time.Hour})
+		Expect(err).To(HaveOccurred())
+		Expect(removed).To(BeEmpty())
+		Expect(removals()).To(BeEmpty())
+	})
+
+	It("only lists resources with the given label", func() {
+		os.Setenv("FAKE_DOCKER_LABEL", "team=gloo")
+		removed, err := docker.CleanupTestResources(ctx, docker.JanitorOptions{Label: "team=gloo"})
+		Expect(err).To(HaveOccurred())
+		Expect(removed[docker.Container]).To(Equal([]string{"old-container"}))
+		Expect(commands()).To(ContainElement("container ls --all --quiet --no-trunc --filter label=team=gloo"))
+	})
+})
@@ -0,0 +1,77 @@
+package errutils
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type timeoutInfoKey struct{}
+
+type timeoutInfo struct {
+	start    time.Time
+	timeout  time.Duration
+	deadline time.Time
+}
+
+// WithTimeout is context.WithTimeout, but also records the timeout and when it started,
+// so that WithTimeoutInfo can include them in the error when the deadline passes
+func WithTimeout(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	return context.WithValue(ctx, timeoutInfoKey{}, timeoutInfo{
+		start:    start,
+		timeout:  timeout,
+		deadline: start.Add(timeout),
+	}), cancel
+}
+
+// TimeoutError is returned by WithTimeoutInfo for operations that failed because their context's deadline passed
+type TimeoutError struct {
+	Operation string
+	// zero if the context wasn't created with WithTimeout
+	Timeout time.Duration
+	Elapsed time.Duration
+	// when the context's deadline passed
+	Deadline time.Time
+	Err      error
+}
+
+func (e *TimeoutError) Error() string {
+	if e.Timeout == 0 {
+		return fmt.Sprintf("%s timed out (deadline %s): %v", e.Operation, e.Deadline.Format(time.RFC3339), e.Err)
+	}
+	return fmt.Sprintf("%s timed out after %s (timeout %s): %v",
+		e.Operation, e.Elapsed.Round(time.Millisecond), e.Timeout, e.Err)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+func (e *TimeoutError) Cause() error {
+	return e.Err
+}
+
+// WithTimeoutInfo returns err as is, unless the operation failed because ctx's deadline passed, in which case
+// the error is wrapped in a *TimeoutError naming the operation and, if ctx was created with WithTimeout, the
+// configured timeout and how long the operation ran. This turns "context deadline exceeded" in CI logs into
+// e.g. "waiting for gloo pods timed out after 2m0.001s (timeout 2m0s): context deadline exceeded".
+func WithTimeoutInfo(ctx context.Context, operation string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, context.DeadlineExceeded) && ctx.Err() != context.DeadlineExceeded {
+		return err
+	}
+	timeoutErr := &TimeoutError{Operation: operation, Err: err}
+	timeoutErr.Deadline, _ = ctx.Deadline()
+	// if the deadline of a parent context passed first, the recorded timeout isn't the one that applied
+	if info, ok := ctx.Value(timeoutInfoKey{}).(timeoutInfo); ok && !info.deadline.After(timeoutErr.Deadline) {
+		timeoutErr.Timeout = info.timeout
+		timeoutErr.Elapsed = time.Since(info.start)
+	}
+	return timeoutErr
+}
@@ -0,0 +1,46 @@
+package errutils_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/solo-io/go-utils/errutils"
+)
+
+var _ = Describe("WithTimeoutInfo", func() {
+
+	It("describes operations that timed out", func() {
+		ctx, cancel := errutils.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		<-ctx.Done()
+		err := errutils.WithTimeoutInfo(ctx, "waiting for pods", errors.Wrap(ctx.Err(), "listing pods"))
+		timeoutErr, ok := err.(*errutils.TimeoutError)
+		Expect(ok).To(BeTrue())
+		Expect(timeoutErr.Timeout).To(Equal(50 * time.Millisecond))
+		Expect(timeoutErr.Elapsed).To(BeNumerically(">=", 50*time.Millisecond))
+		Expect(err.Error()).To(MatchRegexp(`^waiting for pods timed out after \S+ \(timeout 50ms\): listing pods: context deadline exceeded$`))
+		Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+	})
+
+	It("leaves other errors alone", func() {
+		ctx, cancel := errutils.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		err := errors.New("connection refused")
+		Expect(errutils.WithTimeoutInfo(ctx, "waiting for pods", err)).To(Equal(err))
+		Expect(errutils.WithTimeoutInfo(ctx, "waiting for pods", nil)).To(BeNil())
+	})
+
+	It("does not claim a timeout that didn't apply", func() {
+		parent, cancelParent := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancelParent()
+		ctx, cancel := errutils.WithTimeout(parent, time.Minute)
+		defer cancel()
+		<-ctx.Done()
+		err := errutils.WithTimeoutInfo(ctx, "installing", ctx.Err())
+		Expect(err.(*errutils.TimeoutError).Timeout).To(BeZero())
+		Expect(err.Error()).To(HavePrefix("installing timed out (deadline "))
+	})
+})
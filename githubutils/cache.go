@@ -0,0 +1,215 @@
+package githubutils
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/solo-io/go-utils/contextutils"
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+)
+
+// FromCacheHeader is set on responses that were served from the cache after GitHub answered 304 Not Modified
+const FromCacheHeader = "X-From-Cache"
+
+// ResponseCache stores raw HTTP responses for a CachingTransport
+type ResponseCache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, response []byte) error
+}
+
+type memoryCache struct {
+	lock      sync.RWMutex
+	responses map[string][]byte
+}
+
+// NewMemoryCache returns a ResponseCache that lives as long as the process, e.g. for a bot
+func NewMemoryCache() ResponseCache {
+	return &memoryCache{responses: map[string][]byte{}}
+}
+
+func (c *memoryCache) Get(key string) ([]byte, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	response, ok := c.responses[key]
+	return response, ok
+}
+
+func (c *memoryCache) Set(key string, response []byte) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.responses[key] = response
+	return nil
+}
+
+type diskCache struct {
+	dir string
+}
+
+// NewDiskCache returns a ResponseCache that stores responses as files in dir, so that they can be reused
+// across runs of a CI tool, e.g. by caching dir between builds. dir is created if it doesn't exist.
+func NewDiskCache(dir string) ResponseCache {
+	return &diskCache{dir: dir}
+}
+
+func (c *diskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:]))
+}
+
+func (c *diskCache) Get(key string) ([]byte, bool) {
+	response, err := ioutil.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	return response, true
+}
+
+func (c *diskCache) Set(key string, response []byte) error {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return err
+	}
+	// write to a temp file first, so that concurrent runs never read a partial response
+	tmp, err := ioutil.TempFile(c.dir, "tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(response); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path(key))
+}
+
+// responseHeadersNotCached are left out of cached responses, since they are specific to the client that made the
+// request or reveal something about its credentials
+var responseHeadersNotCached = []string{
+	"Set-Cookie",
+	"Set-Cookie2",
+	"X-OAuth-Scopes",
+	"X-Accepted-OAuth-Scopes",
+	"X-OAuth-Client-Id",
+	"GitHub-Authentication-Token-Expiration",
+}
+
+// CachingTransport makes conditional requests for GET requests it has seen before. GitHub answers them with
+// 304 Not Modified if nothing changed, which doesn't count against the rate limit, and the cached response is
+// returned instead. Responses without an ETag or Last-Modified header are not cached.
+// Responses are cached per identity, so a cache shared by clients with different tokens never serves the private
+// responses of one to another.
+type CachingTransport struct {
+	// defaults to http.DefaultTransport
+	Transport http.RoundTripper
+	Cache     ResponseCache
+	// identifies the credentials of the client, e.g. its token, and is only stored hashed. If empty, the
+	// Authorization header of the request is used, which is only set here if Transport doesn't add it itself.
+	Identity string
+}
+
+var _ http.RoundTripper = &CachingTransport{}
+
+func (t *CachingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	if req.Method != http.MethodGet {
+		return transport.RoundTrip(req)
+	}
+
+	key := t.cacheKey(req)
+	cached := t.cachedResponse(req, key)
+	if cached != nil {
+		// a RoundTripper must not modify the request it was given
+		req = req.Clone(req.Context())
+		if etag := cached.Header.Get("ETag"); etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if lastModified := cached.Header.Get("Last-Modified"); lastModified != "" {
+			req.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if cached != nil && resp.StatusCode == http.StatusNotModified {
+		resp.Body.Close()
+		// keep the fresh headers, e.g. the rate limit, which go-github reads from every response
+		for name, values := range resp.Header {
+			cached.Header[name] = values
+		}
+		cached.Header.Set(FromCacheHeader, "1")
+		return cached, nil
+	}
+	if resp.StatusCode == http.StatusOK && (resp.Header.Get("ETag") != "" || resp.Header.Get("Last-Modified") != "") {
+		stored := *resp
+		stored.Header = resp.Header.Clone()
+		for _, name := range responseHeadersNotCached {
+			stored.Header.Del(name)
+		}
+		// DumpResponse replaces the body it reads, so the response can still be returned
+		dump, err := httputil.DumpResponse(&stored, true)
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = stored.Body
+		if err := t.Cache.Set(key, dump); err != nil {
+			contextutils.LoggerFrom(req.Context()).Warnw("Unable to cache GitHub response", zap.Error(err), zap.String("url", req.URL.String()))
+		}
+	}
+	return resp, nil
+}
+
+func (t *CachingTransport) cacheKey(req *http.Request) string {
+	identity := t.Identity
+	if identity == "" {
+		identity = req.Header.Get("Authorization")
+	}
+	identitySum := sha256.Sum256([]byte(identity))
+	// GitHub varies its responses on the Accept header, e.g. for preview APIs
+	return req.URL.String() + " " + req.Header.Get("Accept") + " " + hex.EncodeToString(identitySum[:])
+}
+
+func (t *CachingTransport) cachedResponse(req *http.Request, key string) *http.Response {
+	dump, ok := t.Cache.Get(key)
+	if !ok {
+		return nil
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(dump)), req)
+	if err != nil {
+		// treat a corrupt entry as a miss, it's overwritten by the next response
+		return nil
+	}
+	return resp
+}
+
+// GetCachingClient is GetClient, but with conditional requests backed by cache, see CachingTransport
+func GetCachingClient(ctx context.Context, cache ResponseCache) (*github.Client, error) {
+	token, err := GetGithubToken()
+	if err != nil {
+		return nil, err
+	}
+	ts := oauth2.StaticTokenSource(
+		&oauth2.Token{AccessToken: token},
+	)
+	tc := oauth2.NewClient(ctx, ts)
+	// the oauth2 transport adds the Authorization header after the caching transport sees the request
+	tc.Transport = &CachingTransport{Transport: tc.Transport, Cache: cache, Identity: token}
+	return github.NewClient(tc), nil
+}
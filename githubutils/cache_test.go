@@ -0,0 +1,182 @@
+package githubutils_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/google/go-github/v32/github"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/solo-io/go-utils/githubutils"
+)
+
+var _ = Describe("caching transport", func() {
+	var (
+		ctx         = context.Background()
+		server      *httptest.Server
+		requests    int
+		notModified int
+		etag        string
+	)
+
+	newClientWithIdentity := func(cache githubutils.ResponseCache, identity string) *github.Client {
+		client := github.NewClient(&http.Client{Transport: &githubutils.CachingTransport{Cache: cache, Identity: identity}})
+		client.BaseURL, _ = url.Parse(server.URL + "/")
+		return client
+	}
+
+	newClient := func(cache githubutils.ResponseCache) *github.Client {
+		return newClientWithIdentity(cache, "")
+	}
+
+	BeforeEach(func() {
+		requests, notModified, etag = 0, 0, `"v1"`
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/solo-io/testrepo/releases", func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.Header().Set("X-RateLimit-Remaining", fmt.Sprint(5000-requests))
+			if r.Header.Get("If-None-Match") == etag {
+				notModified++
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", etag)
+			w.Header().Set("Set-Cookie", "session=secret")
+			w.Header().Set("X-OAuth-Scopes", "repo")
+			fmt.Fprintf(w, `[{"tag_name": "v1.0.0", "body": %s}]`, etag)
+		})
+		mux.HandleFunc("/repos/solo-io/testrepo/tags", func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			fmt.Fprint(w, `[{"name": "v1.0.0"}]`)
+		})
+		server = httptest.NewServer(mux)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("serves unchanged responses from the cache", func() {
+		client := newClient(githubutils.NewMemoryCache())
+		releases, _, err := client.Repositories.ListReleases(ctx, "solo-io", "testrepo", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(releases).To(HaveLen(1))
+
+		releases, resp, err := client.Repositories.ListReleases(ctx, "solo-io", "testrepo", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(releases).To(HaveLen(1))
+		Expect(releases[0].GetTagName()).To(Equal("v1.0.0"))
+		Expect(notModified).To(Equal(1))
+		Expect(resp.Header.Get(githubutils.FromCacheHeader)).To(Equal("1"))
+		// the rate limit comes from the fresh response
+		Expect(resp.Rate.Remaining).To(Equal(4998))
+	})
+
+	It("replaces the cached response when it changed", func() {
+		client := newClient(githubutils.NewMemoryCache())
+		_, _, err := client.Repositories.ListReleases(ctx, "solo-io", "testrepo", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		etag = `"v2"`
+		releases, resp, err := client.Repositories.ListReleases(ctx, "solo-io", "testrepo", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(releases[0].GetBody()).To(Equal("v2"))
+		Expect(resp.Header.Get(githubutils.FromCacheHeader)).To(BeEmpty())
+
+		_, resp, err = client.Repositories.ListReleases(ctx, "solo-io", "testrepo", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.Header.Get(githubutils.FromCacheHeader)).To(Equal("1"))
+		Expect(notModified).To(Equal(1))
+	})
+
+	It("doesn't cache responses without validators", func() {
+		client := newClient(githubutils.NewMemoryCache())
+		for i := 0; i < 2; i++ {
+			_, resp, err := client.Repositories.ListTags(ctx, "solo-io", "testrepo", nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Header.Get(githubutils.FromCacheHeader)).To(BeEmpty())
+		}
+		Expect(requests).To(Equal(2))
+	})
+
+	It("persists responses on disk across clients", func() {
+		dir, err := ioutil.TempDir("", "github-cache")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+
+		_, _, err = newClient(githubutils.NewDiskCache(dir)).Repositories.ListReleases(ctx, "solo-io", "testrepo", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		releases, resp, err := newClient(githubutils.NewDiskCache(dir)).Repositories.ListReleases(ctx, "solo-io", "testrepo", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(releases).To(HaveLen(1))
+		Expect(resp.Header.Get(githubutils.FromCacheHeader)).To(Equal("1"))
+	})
+	It("keeps the responses of different identities apart", func() {
+		cache := githubutils.NewMemoryCache()
+		_, _, err := newClientWithIdentity(cache, "token-a").Repositories.ListReleases(ctx, "solo-io", "testrepo", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, resp, err := newClientWithIdentity(cache, "token-b").Repositories.ListReleases(ctx, "solo-io", "testrepo", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.Header.Get(githubutils.FromCacheHeader)).To(BeEmpty())
+
+		_, resp, err = newClientWithIdentity(cache, "token-a").Repositories.ListReleases(ctx, "solo-io", "testrepo", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.Header.Get(githubutils.FromCacheHeader)).To(Equal("1"))
+		Expect(notModified).To(Equal(1))
+	})
+
+	It("uses the Authorization header as the identity if none is set", func() {
+		cache := githubutils.NewMemoryCache()
+		withToken := func(token string) *github.Client {
+			transport := &githubutils.CachingTransport{Cache: cache}
+			client := github.NewClient(&http.Client{Transport: &authTransport{token: token, next: transport}})
+			client.BaseURL, _ = url.Parse(server.URL + "/")
+			return client
+		}
+		_, _, err := withToken("token-a").Repositories.ListReleases(ctx, "solo-io", "testrepo", nil)
+		Expect(err).NotTo(HaveOccurred())
+		_, resp, err := withToken("token-b").Repositories.ListReleases(ctx, "solo-io", "testrepo", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.Header.Get(githubutils.FromCacheHeader)).To(BeEmpty())
+	})
+
+	It("doesn't store cookies or token scopes", func() {
+		dir, err := ioutil.TempDir("", "github-cache")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+
+		_, resp, err := newClient(githubutils.NewDiskCache(dir)).Repositories.ListReleases(ctx, "solo-io", "testrepo", nil)
+		Expect(err).NotTo(HaveOccurred())
+		// the response itself is returned as is
+		Expect(resp.Header.Get("Set-Cookie")).To(Equal("session=secret"))
+
+		files, err := ioutil.ReadDir(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(files).To(HaveLen(1))
+		dump, err := ioutil.ReadFile(filepath.Join(dir, files[0].Name()))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(dump)).To(ContainSubstring("v1.0.0"))
+		Expect(string(dump)).NotTo(ContainSubstring("session=secret"))
+		Expect(string(dump)).NotTo(ContainSubstring("X-Oauth-Scopes"))
+	})
+})
+
+// authTransport adds an Authorization header, like the oauth2 transport would if it wrapped the caching transport
+type authTransport struct {
+	token string
+	next  http.RoundTripper
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "token "+t.token)
+	return t.next.RoundTrip(req)
+}
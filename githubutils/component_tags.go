@@ -0,0 +1,211 @@
+package githubutils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/rotisserie/eris"
+	"github.com/solo-io/go-utils/contextutils"
+	"github.com/solo-io/go-utils/versionutils"
+	"go.uber.org/zap"
+)
+
+// In a monorepo every component is versioned independently with tags of the form
+// <component>/vX.Y.Z, where <component> is the path of the component's directory, e.g. "projects/gloo/v1.2.3".
+
+var (
+	InvalidComponentTagError = func(tag string) error {
+		return eris.Errorf("tag %s is not of the form <component>/vX.Y.Z", tag)
+	}
+	TooManyComponentCommitsError = func(component, previous string, total int) error {
+		return eris.Errorf("unable to list the changes to %s since %s: there are %d commits since, more than GitHub can compare", component, previous, total)
+	}
+)
+
+// ComponentTag returns the tag for version of component
+func ComponentTag(component string, version *versionutils.Version) string {
+	return strings.TrimSuffix(component, "/") + "/" + version.String()
+}
+
+// ParseComponentTag splits a component tag into the component and its version
+func ParseComponentTag(tag string) (string, *versionutils.Version, error) {
+	idx := strings.LastIndex(tag, "/")
+	if idx <= 0 {
+		return "", nil, InvalidComponentTagError(tag)
+	}
+	version, err := versionutils.ParseVersion(tag[idx+1:])
+	if err != nil {
+		return "", nil, InvalidComponentTagError(tag)
+	}
+	return tag[:idx], version, nil
+}
+
+// FindLatestComponentVersions returns the greatest released version of every component in the repo.
+// Tags that are not component tags are ignored.
+func FindLatestComponentVersions(ctx context.Context, client *github.Client, owner, repo string) (map[string]*versionutils.Version, error) {
+	latest := map[string]*versionutils.Version{}
+	for page := 1; ; page++ {
+		tags, resp, err := client.Repositories.ListTags(ctx, owner, repo, &github.ListOptions{Page: page, PerPage: 100})
+		if err != nil {
+			contextutils.LoggerFrom(ctx).Errorw("Unable to list tags", zap.Error(err))
+			return nil, err
+		}
+		for _, tag := range tags {
+			component, version, err := ParseComponentTag(tag.GetName())
+			if err != nil {
+				continue
+			}
+			if current, ok := latest[component]; !ok || version.MustIsGreaterThan(*current) {
+				latest[component] = version
+			}
+		}
+		if resp.NextPage == 0 {
+			return latest, nil
+		}
+	}
+}
+
+// FindLatestComponentVersion returns the greatest released version of component, or
+// versionutils.SemverNilVersionValue if the component has never been released.
+func FindLatestComponentVersion(ctx context.Context, client *github.Client, owner, repo, component string) (string, error) {
+	latest, err := FindLatestComponentVersions(ctx, client, owner, repo)
+	if err != nil {
+		return "", err
+	}
+	version, ok := latest[strings.TrimSuffix(component, "/")]
+	if !ok {
+		return versionutils.SemverNilVersionValue, nil
+	}
+	return version.String(), nil
+}
+
+// GetComponentChangelog lists, newest first, the first line of the message of every commit that touched the
+// component's directory since its previous release, up to and including sha. The commits since the previous
+// release are read from GitHub's compare API, which returns at most 250 commits; if there are more, an error is
+// returned rather than an incomplete changelog. Which of them touched the component is read from the history of
+// the component's directory, a page of up to 100 commits at a time, rather than from every commit.
+func GetComponentChangelog(ctx context.Context, client *github.Client, owner, repo, component, sha string) ([]string, error) {
+	component = strings.TrimSuffix(component, "/")
+	previous, err := FindLatestComponentVersion(ctx, client, owner, repo, component)
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	if previous == versionutils.SemverNilVersionValue {
+		err := walkComponentCommits(ctx, client, owner, repo, component, sha, func(commit *github.RepositoryCommit) bool {
+			lines = append(lines, changelogLine(commit))
+			return true
+		})
+		return lines, err
+	}
+	previousSha, err := getTaggedCommit(ctx, client, owner, repo, component+"/"+previous)
+	if err != nil {
+		return nil, err
+	}
+
+	comparison, _, err := client.Repositories.CompareCommits(ctx, owner, repo, previousSha, sha)
+	if err != nil {
+		contextutils.LoggerFrom(ctx).Errorw("Unable to compare commits", zap.Error(err), zap.String("base", previousSha))
+		return nil, err
+	}
+	if comparison.GetTotalCommits() > len(comparison.Commits) {
+		return nil, TooManyComponentCommitsError(component, previous, comparison.GetTotalCommits())
+	}
+	if len(comparison.Commits) == 0 {
+		return nil, nil
+	}
+	sinceRelease := make(map[string]bool, len(comparison.Commits))
+	var oldest time.Time
+	for _, commit := range comparison.Commits {
+		sinceRelease[commit.GetSHA()] = true
+		if date := commit.GetCommit().GetCommitter().GetDate(); oldest.IsZero() || date.Before(oldest) {
+			oldest = date
+		}
+	}
+	// the history is newest first, so the commits since the release have all been seen once it reaches a commit
+	// older than any of them. Reaching the release isn't enough: commits merged from a branch that started before
+	// the release come after it.
+	err = walkComponentCommits(ctx, client, owner, repo, component, sha, func(commit *github.RepositoryCommit) bool {
+		if commit.GetCommit().GetCommitter().GetDate().Before(oldest) {
+			return false
+		}
+		if sinceRelease[commit.GetSHA()] {
+			lines = append(lines, changelogLine(commit))
+		}
+		return true
+	})
+	return lines, err
+}
+
+// walkComponentCommits calls visit with every commit that touched the component's directory, newest first,
+// starting at sha, until visit returns false
+func walkComponentCommits(ctx context.Context, client *github.Client, owner, repo, component, sha string, visit func(commit *github.RepositoryCommit) bool) error {
+	opts := &github.CommitsListOptions{SHA: sha, Path: component, ListOptions: github.ListOptions{PerPage: 100}}
+	for page := 1; ; page++ {
+		opts.Page = page
+		commits, resp, err := client.Repositories.ListCommits(ctx, owner, repo, opts)
+		if err != nil {
+			contextutils.LoggerFrom(ctx).Errorw("Unable to list commits", zap.Error(err))
+			return err
+		}
+		for _, commit := range commits {
+			if !visit(commit) {
+				return nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return nil
+		}
+	}
+}
+
+// getTaggedCommit returns the sha of the commit a tag points to, peeling annotated tags, whose refs point to
+// the tag object rather than the commit
+func getTaggedCommit(ctx context.Context, client *github.Client, owner, repo, tag string) (string, error) {
+	ref, _, err := client.Git.GetRef(ctx, owner, repo, "tags/"+tag)
+	if err != nil {
+		contextutils.LoggerFrom(ctx).Errorw("Unable to get tag", zap.Error(err), zap.String("tag", tag))
+		return "", err
+	}
+	if ref.GetObject().GetType() != "tag" {
+		return ref.GetObject().GetSHA(), nil
+	}
+	annotated, _, err := client.Git.GetTag(ctx, owner, repo, ref.GetObject().GetSHA())
+	if err != nil {
+		contextutils.LoggerFrom(ctx).Errorw("Unable to get annotated tag", zap.Error(err), zap.String("tag", tag))
+		return "", err
+	}
+	return annotated.GetObject().GetSHA(), nil
+}
+
+func changelogLine(commit *github.RepositoryCommit) string {
+	return strings.SplitN(commit.GetCommit().GetMessage(), "\n", 2)[0]
+}
+
+// CreateComponentRelease tags sha as the given version of component and publishes a release whose body is
+// the component's changelog since its previous release.
+func CreateComponentRelease(ctx context.Context, client *github.Client, owner, repo, component, sha string, version *versionutils.Version) (*github.RepositoryRelease, error) {
+	changelog, err := GetComponentChangelog(ctx, client, owner, repo, component, sha)
+	if err != nil {
+		return nil, err
+	}
+	body := "This release contained no changes."
+	if len(changelog) > 0 {
+		body = "- " + strings.Join(changelog, "\n- ")
+	}
+	tag := ComponentTag(component, version)
+	release, _, err := client.Repositories.CreateRelease(ctx, owner, repo, &github.RepositoryRelease{
+		TagName:         github.String(tag),
+		TargetCommitish: github.String(sha),
+		Name:            github.String(fmt.Sprintf("%s %s", strings.TrimSuffix(component, "/"), version.String())),
+		Body:            github.String(body),
+	})
+	if err != nil {
+		contextutils.LoggerFrom(ctx).Errorw("Unable to create component release", zap.Error(err), zap.String("tag", tag))
+		return nil, err
+	}
+	return release, nil
+}
@@ -0,0 +1,206 @@
+package githubutils_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/solo-io/go-utils/githubutils"
+	"github.com/solo-io/go-utils/versionutils"
+)
+
+var _ = Describe("component tags", func() {
+
+	It("round trips component tags", func() {
+		tag := githubutils.ComponentTag("projects/gloo/", versionutils.NewVersion(1, 2, 3, "", 0))
+		Expect(tag).To(Equal("projects/gloo/v1.2.3"))
+		component, version, err := githubutils.ParseComponentTag(tag)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(component).To(Equal("projects/gloo"))
+		Expect(version.String()).To(Equal("v1.2.3"))
+	})
+
+	It("rejects tags without a component", func() {
+		_, _, err := githubutils.ParseComponentTag("v1.2.3")
+		Expect(err).To(HaveOccurred())
+		_, _, err = githubutils.ParseComponentTag("gloo/latest")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("finds the latest version of each component", func() {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/solo-io/monorepo/tags", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `[{"name": "gloo/v1.2.0"}, {"name": "gloo/v1.10.0"}, {"name": "envoy/v0.1.0"}, {"name": "v3.0.0"}]`)
+		})
+		server := httptest.NewServer(mux)
+		defer server.Close()
+		client := github.NewClient(nil)
+		client.BaseURL, _ = url.Parse(server.URL + "/")
+
+		versions, err := githubutils.FindLatestComponentVersions(context.Background(), client, "solo-io", "monorepo")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(versions).To(HaveLen(2))
+		Expect(versions["gloo"].String()).To(Equal("v1.10.0"))
+		Expect(versions["envoy"].String()).To(Equal("v0.1.0"))
+
+		version, err := githubutils.FindLatestComponentVersion(context.Background(), client, "solo-io", "monorepo", "other")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(version).To(Equal(versionutils.SemverNilVersionValue))
+	})
+	Context("changelogs and releases", func() {
+		var (
+			ctx      = context.Background()
+			mux      *http.ServeMux
+			server   *httptest.Server
+			client   *github.Client
+			tags     string
+			released *github.RepositoryRelease
+		)
+
+		BeforeEach(func() {
+			tags = `[{"name": "gloo/v1.0.0"}, {"name": "envoy/v0.1.0"}]`
+			released = nil
+			mux = http.NewServeMux()
+			mux.HandleFunc("/repos/solo-io/monorepo/tags", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, tags)
+			})
+			// an annotated tag, whose ref points to the tag object
+			mux.HandleFunc("/repos/solo-io/monorepo/git/ref/tags/gloo/v1.0.0", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"ref": "refs/tags/gloo/v1.0.0", "object": {"type": "tag", "sha": "tagobject"}}`)
+			})
+			mux.HandleFunc("/repos/solo-io/monorepo/git/tags/tagobject", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"sha": "tagobject", "object": {"type": "commit", "sha": "released"}}`)
+			})
+			mux.HandleFunc("/repos/solo-io/monorepo/compare/released...head", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintf(w, `{"total_commits": 4, "commits": [%s, %s, %s, %s]}`,
+					historyCommit("c1"), historyCommit("c2"), historyCommit("c3"), historyCommit("head"))
+			})
+			// only the history of a directory is listed, there is no handler for getting single commits
+			mux.HandleFunc("/repos/solo-io/monorepo/commits", func(w http.ResponseWriter, r *http.Request) {
+				var commits []string
+				started := false
+				for _, commit := range history {
+					started = started || commit.sha == r.URL.Query().Get("sha")
+					if commit.branch && r.URL.Query().Get("sha") != "merged" {
+						continue
+					}
+					if started && strings.HasPrefix(commit.file, r.URL.Query().Get("path")+"/") {
+						commits = append(commits, historyCommit(commit.sha))
+					}
+				}
+				fmt.Fprintf(w, "[%s]", strings.Join(commits, ","))
+			})
+			mux.HandleFunc("/repos/solo-io/monorepo/releases", func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.Method).To(Equal(http.MethodPost))
+				released = &github.RepositoryRelease{}
+				Expect(json.NewDecoder(r.Body).Decode(released)).To(Succeed())
+				fmt.Fprint(w, `{"id": 1}`)
+			})
+			server = httptest.NewServer(mux)
+			client = github.NewClient(nil)
+			client.BaseURL, _ = url.Parse(server.URL + "/")
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("lists the commits that touched the component since its annotated release tag", func() {
+			changelog, err := githubutils.GetComponentChangelog(ctx, client, "solo-io", "monorepo", "gloo/", "head")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changelog).To(Equal([]string{"gloo: move docs", "gloo: fix retries"}))
+		})
+
+		It("peels lightweight tags too", func() {
+			tags = `[{"name": "envoy/v0.1.0"}]`
+			mux.HandleFunc("/repos/solo-io/monorepo/git/ref/tags/envoy/v0.1.0", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"ref": "refs/tags/envoy/v0.1.0", "object": {"type": "commit", "sha": "released"}}`)
+			})
+			changelog, err := githubutils.GetComponentChangelog(ctx, client, "solo-io", "monorepo", "envoy", "head")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changelog).To(Equal([]string{"envoy: bump"}))
+		})
+
+		It("lists the whole history of the component for its first release", func() {
+			tags = `[]`
+			changelog, err := githubutils.GetComponentChangelog(ctx, client, "solo-io", "monorepo", "gloo", "head")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changelog).To(Equal([]string{"gloo: move docs", "gloo: fix retries", "gloo: release 1.0", "gloo: initial"}))
+		})
+
+		It("includes commits since the release that are older than it", func() {
+			// merged after the release from a branch that started before it
+			mux.HandleFunc("/repos/solo-io/monorepo/compare/released...merged", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintf(w, `{"total_commits": 2, "commits": [%s, %s]}`, historyCommit("c0.5"), historyCommit("merged"))
+			})
+			changelog, err := githubutils.GetComponentChangelog(ctx, client, "solo-io", "monorepo", "gloo", "merged")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changelog).To(Equal([]string{"gloo: merge the retries branch", "gloo: start retries"}))
+		})
+
+		It("fails rather than return an incomplete changelog", func() {
+			mux.HandleFunc("/repos/solo-io/monorepo/compare/released...big", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"total_commits": 300, "commits": [{"sha": "c1"}]}`)
+			})
+			_, err := githubutils.GetComponentChangelog(ctx, client, "solo-io", "monorepo", "gloo", "big")
+			Expect(err).To(MatchError(ContainSubstring("there are 300 commits since")))
+		})
+
+		It("creates a release with the changelog", func() {
+			_, err := githubutils.CreateComponentRelease(ctx, client, "solo-io", "monorepo", "gloo", "head", versionutils.NewVersion(1, 1, 0, "", 0))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(released.GetTagName()).To(Equal("gloo/v1.1.0"))
+			Expect(released.GetTargetCommitish()).To(Equal("head"))
+			Expect(released.GetName()).To(Equal("gloo v1.1.0"))
+			Expect(released.GetBody()).To(Equal("- gloo: move docs\n- gloo: fix retries"))
+		})
+
+		It("says when a release has no changes", func() {
+			mux.HandleFunc("/repos/solo-io/monorepo/compare/released...c2", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintf(w, `{"total_commits": 1, "commits": [%s]}`, historyCommit("c2"))
+			})
+			_, err := githubutils.CreateComponentRelease(ctx, client, "solo-io", "monorepo", "gloo", "c2", versionutils.NewVersion(1, 0, 1, "", 0))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(released.GetBody()).To(Equal("This release contained no changes."))
+		})
+	})
+})
+
+type commitInHistory struct {
+	sha, message string
+	// the file the commit changed
+	file string
+	day  int
+	// only reachable from "merged", which merges the branch
+	branch bool
+}
+
+// the history of the monorepo, newest first
+var history = []commitInHistory{
+	{sha: "merged", message: "gloo: merge the retries branch", file: "gloo/retry.go", day: 7},
+	{sha: "head", message: "gloo: move docs", file: "gloo/README.md", day: 6},
+	{sha: "c3", message: "glooctl: add flag", file: "glooctl/main.go", day: 5},
+	{sha: "c2", message: "envoy: bump", file: "envoy/Makefile", day: 4},
+	{sha: "c1", message: "gloo: fix retries\n\nlong description", file: "gloo/retry.go", day: 3},
+	{sha: "released", message: "gloo: release 1.0", file: "gloo/version.go", day: 2},
+	{sha: "c0.5", message: "gloo: start retries", file: "gloo/retry.go", day: 1, branch: true},
+	{sha: "c0", message: "gloo: initial", file: "gloo/main.go", day: 0},
+}
+
+func historyCommit(sha string) string {
+	for _, commit := range history {
+		if commit.sha == sha {
+			date := time.Date(2020, 1, 1+commit.day, 0, 0, 0, 0, time.UTC).Format(time.RFC3339)
+			return fmt.Sprintf(`{"sha": %q, "commit": {"message": %q, "committer": {"date": %q}}}`, commit.sha, commit.message, date)
+		}
+	}
+	panic("no commit " + sha)
+}
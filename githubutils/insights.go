@@ -0,0 +1,163 @@
+package githubutils
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/solo-io/go-utils/contextutils"
+	"go.uber.org/zap"
+)
+
+// TrafficCount is the traffic of a repo on one day
+type TrafficCount struct {
+	Day     time.Time `json:"day"`
+	Count   int       `json:"count"`
+	Uniques int       `json:"uniques"`
+}
+
+// Traffic is how often a repo was viewed and cloned. GitHub only keeps the last 14 days.
+type Traffic struct {
+	Views        int            `json:"views"`
+	UniqueViews  int            `json:"uniqueViews"`
+	DailyViews   []TrafficCount `json:"dailyViews"`
+	Clones       int            `json:"clones"`
+	UniqueClones int            `json:"uniqueClones"`
+	DailyClones  []TrafficCount `json:"dailyClones"`
+}
+
+// RepoInsights are adoption metrics of a repo at a point in time, e.g. for a dashboard
+type RepoInsights struct {
+	Owner       string    `json:"owner"`
+	Repo        string    `json:"repo"`
+	CollectedAt time.Time `json:"collectedAt"`
+	Traffic     *Traffic  `json:"traffic"`
+	// downloads of the assets of each release, by tag
+	ReleaseDownloads map[string]int `json:"releaseDownloads"`
+	Stars            int            `json:"stars"`
+	// stars added since the time passed to CollectRepoInsights
+	NewStars int `json:"newStars"`
+}
+
+// CollectRepoInsights collects the traffic, release download counts and stars of a repo. Reading traffic requires
+// push access to the repo. Collecting insights repeatedly, e.g. from a cron job, is much cheaper with a client
+// from GetCachingClient, since most of the responses rarely change.
+func CollectRepoInsights(ctx context.Context, client *github.Client, owner, repo string, starsSince time.Time) (*RepoInsights, error) {
+	insights := &RepoInsights{Owner: owner, Repo: repo, CollectedAt: time.Now().UTC()}
+	var err error
+	if insights.Traffic, err = GetTraffic(ctx, client, owner, repo); err != nil {
+		return nil, err
+	}
+	if insights.ReleaseDownloads, err = GetReleaseDownloadCounts(ctx, client, owner, repo); err != nil {
+		return nil, err
+	}
+	if insights.Stars, insights.NewStars, err = CountStargazers(ctx, client, owner, repo, starsSince); err != nil {
+		return nil, err
+	}
+	return insights, nil
+}
+
+// GetTraffic returns the daily views and clones of a repo over the last 14 days
+func GetTraffic(ctx context.Context, client *github.Client, owner, repo string) (*Traffic, error) {
+	perDay := &github.TrafficBreakdownOptions{Per: "day"}
+	views, _, err := client.Repositories.ListTrafficViews(ctx, owner, repo, perDay)
+	if err != nil {
+		contextutils.LoggerFrom(ctx).Errorw("Unable to get traffic views", zap.Error(err))
+		return nil, err
+	}
+	clones, _, err := client.Repositories.ListTrafficClones(ctx, owner, repo, perDay)
+	if err != nil {
+		contextutils.LoggerFrom(ctx).Errorw("Unable to get traffic clones", zap.Error(err))
+		return nil, err
+	}
+	return &Traffic{
+		Views:        views.GetCount(),
+		UniqueViews:  views.GetUniques(),
+		DailyViews:   trafficCounts(views.Views),
+		Clones:       clones.GetCount(),
+		UniqueClones: clones.GetUniques(),
+		DailyClones:  trafficCounts(clones.Clones),
+	}, nil
+}
+
+func trafficCounts(data []*github.TrafficData) []TrafficCount {
+	counts := make([]TrafficCount, 0, len(data))
+	for _, day := range data {
+		counts = append(counts, TrafficCount{
+			Day:     day.GetTimestamp().UTC(),
+			Count:   day.GetCount(),
+			Uniques: day.GetUniques(),
+		})
+	}
+	return counts
+}
+
+// GetReleaseDownloadCounts returns the total downloads of the assets of every release of a repo, by tag
+func GetReleaseDownloadCounts(ctx context.Context, client *github.Client, owner, repo string) (map[string]int, error) {
+	downloads := map[string]int{}
+	for page := 1; ; page++ {
+		releases, resp, err := client.Repositories.ListReleases(ctx, owner, repo, &github.ListOptions{Page: page, PerPage: 100})
+		if err != nil {
+			contextutils.LoggerFrom(ctx).Errorw("Unable to list releases", zap.Error(err))
+			return nil, err
+		}
+		for _, release := range releases {
+			total := 0
+			for _, asset := range release.Assets {
+				total += asset.GetDownloadCount()
+			}
+			downloads[release.GetTagName()] = total
+		}
+		if resp.NextPage == 0 {
+			return downloads, nil
+		}
+	}
+}
+
+// CountStargazers returns how many stars a repo has, and how many of them were added after since.
+// Stargazers are listed oldest first, so only the pages with stars newer than since are read.
+func CountStargazers(ctx context.Context, client *github.Client, owner, repo string, since time.Time) (total, added int, err error) {
+	repository, _, err := client.Repositories.Get(ctx, owner, repo)
+	if err != nil {
+		contextutils.LoggerFrom(ctx).Errorw("Unable to get repo", zap.Error(err))
+		return 0, 0, err
+	}
+	total = repository.GetStargazersCount()
+
+	listPage := func(page int) ([]*github.Stargazer, *github.Response, error) {
+		stargazers, resp, err := client.Activity.ListStargazers(ctx, owner, repo, &github.ListOptions{Page: page, PerPage: 100})
+		if err != nil {
+			contextutils.LoggerFrom(ctx).Errorw("Unable to list stargazers", zap.Error(err))
+		}
+		return stargazers, resp, err
+	}
+	// countNew returns whether the page also had stars that are older than since
+	countNew := func(stargazers []*github.Stargazer) bool {
+		older := false
+		for _, stargazer := range stargazers {
+			if stargazer.GetStarredAt().After(since) {
+				added++
+			} else {
+				older = true
+			}
+		}
+		return older
+	}
+
+	firstPage, resp, err := listPage(1)
+	if err != nil {
+		return 0, 0, err
+	}
+	// the first page is also the last one if there is no link to a last page
+	for page := resp.LastPage; page > 1; page-- {
+		stargazers, _, err := listPage(page)
+		if err != nil {
+			return 0, 0, err
+		}
+		if countNew(stargazers) {
+			return total, added, nil
+		}
+	}
+	countNew(firstPage)
+	return total, added, nil
+}
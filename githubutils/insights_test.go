@@ -0,0 +1,87 @@
+package githubutils_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/solo-io/go-utils/githubutils"
+)
+
+var _ = Describe("repo insights", func() {
+	var (
+		ctx           = context.Background()
+		server        *httptest.Server
+		client        *github.Client
+		stargazerHits []string
+		since         = time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	)
+
+	BeforeEach(func() {
+		stargazerHits = nil
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/solo-io/testrepo", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"stargazers_count": 5}`)
+		})
+		mux.HandleFunc("/repos/solo-io/testrepo/traffic/views", func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Query().Get("per")).To(Equal("day"))
+			fmt.Fprint(w, `{"count": 30, "uniques": 10, "views": [{"timestamp": "2020-06-01T00:00:00Z", "count": 30, "uniques": 10}]}`)
+		})
+		mux.HandleFunc("/repos/solo-io/testrepo/traffic/clones", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"count": 4, "uniques": 2, "clones": []}`)
+		})
+		mux.HandleFunc("/repos/solo-io/testrepo/releases", func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("page") == "2" {
+				fmt.Fprint(w, `[{"tag_name": "v1.0.0", "assets": []}]`)
+				return
+			}
+			w.Header().Set("Link", fmt.Sprintf(`<%s/repos/solo-io/testrepo/releases?page=2>; rel="next"`, server.URL))
+			fmt.Fprint(w, `[{"tag_name": "v1.1.0", "assets": [{"download_count": 7}, {"download_count": 3}]}]`)
+		})
+		mux.HandleFunc("/repos/solo-io/testrepo/stargazers", func(w http.ResponseWriter, r *http.Request) {
+			page := r.URL.Query().Get("page")
+			stargazerHits = append(stargazerHits, page)
+			switch page {
+			case "1":
+				w.Header().Set("Link", fmt.Sprintf(`<%s/repos/solo-io/testrepo/stargazers?page=3>; rel="last"`, server.URL))
+				fmt.Fprint(w, `[{"starred_at": "2019-01-01T00:00:00Z"}, {"starred_at": "2019-02-01T00:00:00Z"}]`)
+			case "2":
+				fmt.Fprint(w, `[{"starred_at": "2020-05-01T00:00:00Z"}, {"starred_at": "2020-06-02T00:00:00Z"}]`)
+			case "3":
+				fmt.Fprint(w, `[{"starred_at": "2020-07-01T00:00:00Z"}]`)
+			}
+		})
+		server = httptest.NewServer(mux)
+		client = github.NewClient(nil)
+		client.BaseURL, _ = url.Parse(server.URL + "/")
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("collects traffic, downloads and stars", func() {
+		insights, err := githubutils.CollectRepoInsights(ctx, client, "solo-io", "testrepo", since)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(insights.Traffic.Views).To(Equal(30))
+		Expect(insights.Traffic.UniqueViews).To(Equal(10))
+		Expect(insights.Traffic.DailyViews).To(Equal([]githubutils.TrafficCount{{Day: since, Count: 30, Uniques: 10}}))
+		Expect(insights.Traffic.Clones).To(Equal(4))
+		Expect(insights.ReleaseDownloads).To(Equal(map[string]int{"v1.1.0": 10, "v1.0.0": 0}))
+		Expect(insights.Stars).To(Equal(5))
+		Expect(insights.NewStars).To(Equal(2))
+	})
+
+	It("only reads the pages of stargazers newer than since", func() {
+		_, added, err := githubutils.CountStargazers(ctx, client, "solo-io", "testrepo", since)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(added).To(Equal(2))
+		Expect(stargazerHits).To(Equal([]string{"1", "3", "2"}))
+	})
+})
@@ -0,0 +1,114 @@
+package githubutils
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/solo-io/go-utils/contextutils"
+	"go.uber.org/zap"
+)
+
+type ReleasePreflightOptions struct {
+	// the tag the release will be created from
+	Tag string
+	// the release name, defaults to Tag
+	Name string
+	// the branch the tag will be cut from. If empty, the branch checks are skipped
+	TargetBranch string
+	// if set, cutting a release from an unprotected branch is an error
+	RequireProtectedBranch bool
+}
+
+type errorTagAlreadyExists struct {
+	tag, sha string
+}
+
+func (e *errorTagAlreadyExists) Error() string {
+	return fmt.Sprintf("Tag %s already exists and points to %s. Tags are immutable once released; "+
+		"bump the version instead of re-tagging", e.tag, e.sha)
+}
+
+func IsTagAlreadyExistsError(err error) bool {
+	_, ok := err.(*errorTagAlreadyExists)
+	return ok
+}
+
+type errorReleaseAlreadyPublished struct {
+	name, url string
+}
+
+func (e *errorReleaseAlreadyPublished) Error() string {
+	return fmt.Sprintf("Release %s is already published at %s. Published releases must not be overwritten; "+
+		"delete it manually if it was created by mistake", e.name, e.url)
+}
+
+func IsReleaseAlreadyPublishedError(err error) bool {
+	_, ok := err.(*errorReleaseAlreadyPublished)
+	return ok
+}
+
+type errorBranchNotProtected struct {
+	branch string
+}
+
+func (e *errorBranchNotProtected) Error() string {
+	return fmt.Sprintf("Branch %s is not protected. Releases may only be cut from protected branches; "+
+		"enable branch protection or release from a different branch", e.branch)
+}
+
+func IsBranchNotProtectedError(err error) bool {
+	_, ok := err.(*errorBranchNotProtected)
+	return ok
+}
+
+// ValidateReleasePreflight checks that a release can be created safely: the tag does not exist yet,
+// no published release exists with the same tag or name, and (optionally) the target branch is protected.
+// It returns the first violation found, which can be checked with the Is*Error functions in this package.
+func ValidateReleasePreflight(ctx context.Context, client *github.Client, owner, repo string, opts ReleasePreflightOptions) error {
+	logger := contextutils.LoggerFrom(ctx)
+	if opts.Name == "" {
+		opts.Name = opts.Tag
+	}
+
+	ref, resp, err := client.Git.GetRef(ctx, owner, repo, "tags/"+opts.Tag)
+	if err == nil {
+		return &errorTagAlreadyExists{tag: opts.Tag, sha: ref.GetObject().GetSHA()}
+	}
+	if resp == nil || resp.StatusCode != http.StatusNotFound {
+		logger.Errorw("Unable to determine whether tag exists", zap.Error(err), zap.String("tag", opts.Tag))
+		return err
+	}
+
+	for page := 1; ; page++ {
+		releases, resp, err := client.Repositories.ListReleases(ctx, owner, repo, &github.ListOptions{Page: page})
+		if err != nil {
+			return err
+		}
+		for _, release := range releases {
+			if release.GetDraft() {
+				continue
+			}
+			if release.GetTagName() == opts.Tag || release.GetName() == opts.Name {
+				return &errorReleaseAlreadyPublished{name: opts.Name, url: release.GetHTMLURL()}
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+	}
+
+	if opts.TargetBranch == "" {
+		return nil
+	}
+	branch, _, err := client.Repositories.GetBranch(ctx, owner, repo, opts.TargetBranch)
+	if err != nil {
+		logger.Errorw("Unable to get target branch", zap.Error(err), zap.String("branch", opts.TargetBranch))
+		return err
+	}
+	if opts.RequireProtectedBranch && !branch.GetProtected() {
+		return &errorBranchNotProtected{branch: opts.TargetBranch}
+	}
+	return nil
+}
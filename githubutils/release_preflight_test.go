@@ -0,0 +1,86 @@
+package githubutils_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/google/go-github/v32/github"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/solo-io/go-utils/githubutils"
+)
+
+var _ = Describe("release preflight", func() {
+	var (
+		ctx      = context.Background()
+		server   *httptest.Server
+		mux      *http.ServeMux
+		client   *github.Client
+		releases string
+		opts     githubutils.ReleasePreflightOptions
+	)
+
+	BeforeEach(func() {
+		releases = `[]`
+		opts = githubutils.ReleasePreflightOptions{
+			Tag:                    "v1.0.0",
+			TargetBranch:           "master",
+			RequireProtectedBranch: true,
+		}
+		mux = http.NewServeMux()
+		mux.HandleFunc("/repos/solo-io/testrepo/git/ref/tags/v0.9.0", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"ref": "refs/tags/v0.9.0", "object": {"sha": "abc123"}}`)
+		})
+		mux.HandleFunc("/repos/solo-io/testrepo/git/ref/tags/v1.0.0", func(w http.ResponseWriter, r *http.Request) {
+			http.NotFound(w, r)
+		})
+		mux.HandleFunc("/repos/solo-io/testrepo/releases", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, releases)
+		})
+		mux.HandleFunc("/repos/solo-io/testrepo/branches/master", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"name": "master", "protected": true}`)
+		})
+		mux.HandleFunc("/repos/solo-io/testrepo/branches/feature", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"name": "feature", "protected": false}`)
+		})
+		server = httptest.NewServer(mux)
+		client = github.NewClient(nil)
+		client.BaseURL, _ = url.Parse(server.URL + "/")
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("passes for a new tag", func() {
+		err := githubutils.ValidateReleasePreflight(ctx, client, "solo-io", "testrepo", opts)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("fails when the tag exists", func() {
+		opts.Tag = "v0.9.0"
+		err := githubutils.ValidateReleasePreflight(ctx, client, "solo-io", "testrepo", opts)
+		Expect(githubutils.IsTagAlreadyExistsError(err)).To(BeTrue())
+	})
+
+	It("fails when a release with the same name is published", func() {
+		releases = `[{"tag_name": "v1.0.0-draft", "name": "v1.0.0", "draft": false}]`
+		err := githubutils.ValidateReleasePreflight(ctx, client, "solo-io", "testrepo", opts)
+		Expect(githubutils.IsReleaseAlreadyPublishedError(err)).To(BeTrue())
+	})
+
+	It("ignores draft releases", func() {
+		releases = `[{"tag_name": "v1.0.0", "name": "v1.0.0", "draft": true}]`
+		err := githubutils.ValidateReleasePreflight(ctx, client, "solo-io", "testrepo", opts)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("fails when the target branch is not protected", func() {
+		opts.TargetBranch = "feature"
+		err := githubutils.ValidateReleasePreflight(ctx, client, "solo-io", "testrepo", opts)
+		Expect(githubutils.IsBranchNotProtectedError(err)).To(BeTrue())
+	})
+})
@@ -0,0 +1,97 @@
+package githubutils
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/rotisserie/eris"
+	"github.com/solo-io/go-utils/contextutils"
+	"go.uber.org/zap"
+)
+
+// MaxCommentLength is the most characters GitHub accepts in the body of an issue or PR comment
+const MaxCommentLength = 65536
+
+// Report is a large piece of text, e.g. scan results or an e2e summary, to link to from a PR comment
+type Report struct {
+	// the name of the file the report is published as, e.g. "scan-results.md"
+	FileName string
+	// a short summary that goes in the comment, e.g. "3 high severity vulnerabilities found"
+	Summary string
+	Content string
+}
+
+// PublishReportAsGist creates a secret gist with the content of the report, returning its URL.
+// The GITHUB_TOKEN of a GitHub Actions workflow can't create gists, so the client needs a token with the
+// gist scope.
+func PublishReportAsGist(ctx context.Context, client *github.Client, report Report) (string, error) {
+	gist, _, err := client.Gists.Create(ctx, &github.Gist{
+		Description: github.String(report.Summary),
+		Public:      github.Bool(false),
+		Files: map[github.GistFilename]github.GistFile{
+			github.GistFilename(report.FileName): {Content: github.String(report.Content)},
+		},
+	})
+	if err != nil {
+		contextutils.LoggerFrom(ctx).Errorw("Unable to create gist", zap.Error(err), zap.String("file", report.FileName))
+		return "", err
+	}
+	return gist.GetHTMLURL(), nil
+}
+
+// CommentWithReport comments on a PR with the summary of the report. If the whole report fits in the comment,
+// it is added below the summary in a collapsed section; otherwise the report is published as a gist, see
+// PublishReportAsGist, and the comment links to it, instead of the report being truncated.
+func CommentWithReport(ctx context.Context, client *github.Client, owner, repo string, pr int, report Report) (*github.IssueComment, error) {
+	body := inlineReportComment(report)
+	if utf8.RuneCountInString(body) > MaxCommentLength {
+		url, err := PublishReportAsGist(ctx, client, report)
+		if err != nil {
+			return nil, err
+		}
+		body = fmt.Sprintf("%s\n\nThe full report is too large for a comment, see [%s](%s).", report.Summary, report.FileName, url)
+	}
+	return createReportComment(ctx, client, owner, repo, pr, body)
+}
+
+// CommentWithArtifactLink comments on a PR with the summary of a report that was uploaded as an artifact of the
+// current workflow run, e.g. with the actions/upload-artifact action, linking to the run. Artifacts can only be
+// uploaded from within the run, so uploading the report is left to the workflow.
+func CommentWithArtifactLink(ctx context.Context, client *github.Client, owner, repo string, pr int, summary, artifactName string) (*github.IssueComment, error) {
+	runURL := WorkflowRunURL()
+	if runURL == "" {
+		return nil, eris.Errorf("unable to link to artifact %s, not running in a GitHub Actions workflow", artifactName)
+	}
+	body := fmt.Sprintf("%s\n\nThe full report is in the `%s` artifact of [this workflow run](%s).", summary, artifactName, runURL)
+	return createReportComment(ctx, client, owner, repo, pr, body)
+}
+
+// WorkflowRunURL returns the URL of the current GitHub Actions workflow run, which lists its artifacts,
+// or "" if not running in a workflow
+func WorkflowRunURL() string {
+	serverURL, repository, runID := os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_REPOSITORY"), os.Getenv("GITHUB_RUN_ID")
+	if repository == "" || runID == "" {
+		return ""
+	}
+	if serverURL == "" {
+		serverURL = "https://github.com"
+	}
+	return fmt.Sprintf("%s/%s/actions/runs/%s", strings.TrimSuffix(serverURL, "/"), repository, runID)
+}
+
+func inlineReportComment(report Report) string {
+	return fmt.Sprintf("%s\n\n<details>\n<summary>%s</summary>\n\n%s\n</details>", report.Summary, report.FileName, report.Content)
+}
+
+func createReportComment(ctx context.Context, client *github.Client, owner, repo string, pr int, body string) (*github.IssueComment, error) {
+	comment, _, err := client.Issues.CreateComment(ctx, owner, repo, pr, &github.IssueComment{Body: github.String(body)})
+	if err != nil {
+		contextutils.LoggerFrom(ctx).Errorw("Unable to comment on PR", zap.Error(err), zap.Int("pr", pr))
+		return nil, err
+	}
+	return comment, nil
+}
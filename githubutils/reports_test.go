@@ -0,0 +1,112 @@
+package githubutils_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/google/go-github/v32/github"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/solo-io/go-utils/githubutils"
+)
+
+var _ = Describe("reports", func() {
+	var (
+		ctx      = context.Background()
+		server   *httptest.Server
+		client   *github.Client
+		gists    []*github.Gist
+		comments []*github.IssueComment
+	)
+
+	BeforeEach(func() {
+		gists, comments = nil, nil
+		mux := http.NewServeMux()
+		mux.HandleFunc("/gists", func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Method).To(Equal(http.MethodPost))
+			var gist github.Gist
+			Expect(json.NewDecoder(r.Body).Decode(&gist)).To(Succeed())
+			gists = append(gists, &gist)
+			fmt.Fprint(w, `{"html_url": "https://gist.github.com/bot/abc123"}`)
+		})
+		mux.HandleFunc("/repos/solo-io/testrepo/issues/12/comments", func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Method).To(Equal(http.MethodPost))
+			var comment github.IssueComment
+			Expect(json.NewDecoder(r.Body).Decode(&comment)).To(Succeed())
+			comments = append(comments, &comment)
+			fmt.Fprint(w, `{"id": 1}`)
+		})
+		server = httptest.NewServer(mux)
+		client = github.NewClient(nil)
+		client.BaseURL, _ = url.Parse(server.URL + "/")
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("adds small reports to the comment", func() {
+		report := githubutils.Report{FileName: "scan.md", Summary: "2 issues found", Content: "* issue 1\n* issue 2"}
+		_, err := githubutils.CommentWithReport(ctx, client, "solo-io", "testrepo", 12, report)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gists).To(BeEmpty())
+		Expect(comments).To(HaveLen(1))
+		Expect(comments[0].GetBody()).To(HavePrefix("2 issues found\n\n<details>"))
+		Expect(comments[0].GetBody()).To(ContainSubstring("* issue 1\n* issue 2"))
+	})
+
+	It("publishes large reports as gists", func() {
+		content := strings.Repeat("x", githubutils.MaxCommentLength)
+		report := githubutils.Report{FileName: "e2e.md", Summary: "e2e passed", Content: content}
+		_, err := githubutils.CommentWithReport(ctx, client, "solo-io", "testrepo", 12, report)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gists).To(HaveLen(1))
+		Expect(gists[0].GetPublic()).To(BeFalse())
+		file := gists[0].Files["e2e.md"]
+		Expect(file.GetContent()).To(Equal(content))
+		Expect(comments).To(HaveLen(1))
+		Expect(comments[0].GetBody()).To(Equal("e2e passed\n\nThe full report is too large for a comment, see [e2e.md](https://gist.github.com/bot/abc123)."))
+	})
+
+	Context("artifacts", func() {
+		var oldEnv map[string]string
+
+		BeforeEach(func() {
+			oldEnv = map[string]string{}
+			for _, envVar := range []string{"GITHUB_SERVER_URL", "GITHUB_REPOSITORY", "GITHUB_RUN_ID"} {
+				oldEnv[envVar] = os.Getenv(envVar)
+				os.Unsetenv(envVar)
+			}
+		})
+
+		AfterEach(func() {
+			for envVar, value := range oldEnv {
+				os.Setenv(envVar, value)
+			}
+		})
+
+		It("links to the workflow run", func() {
+			os.Setenv("GITHUB_REPOSITORY", "solo-io/testrepo")
+			os.Setenv("GITHUB_RUN_ID", "42")
+			Expect(githubutils.WorkflowRunURL()).To(Equal("https://github.com/solo-io/testrepo/actions/runs/42"))
+			_, err := githubutils.CommentWithArtifactLink(ctx, client, "solo-io", "testrepo", 12, "scan done", "scan-results")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(comments).To(HaveLen(1))
+			Expect(comments[0].GetBody()).To(Equal("scan done\n\nThe full report is in the `scan-results` artifact of " +
+				"[this workflow run](https://github.com/solo-io/testrepo/actions/runs/42)."))
+		})
+
+		It("fails outside of a workflow", func() {
+			Expect(githubutils.WorkflowRunURL()).To(BeEmpty())
+			_, err := githubutils.CommentWithArtifactLink(ctx, client, "solo-io", "testrepo", 12, "scan done", "scan-results")
+			Expect(err).To(HaveOccurred())
+			Expect(comments).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,118 @@
+package githubutils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/solo-io/go-utils/contextutils"
+	"go.uber.org/zap"
+)
+
+// SignaturePolicy describes which signatures are trusted. Verification is done by GitHub, which only reports a
+// signature (GPG, SSH or S/MIME) as verified if the signing key belongs to the account owning the committer's
+// (or tagger's) email. Keyless Sigstore (gitsign) signatures aren't verified by GitHub, and verifying them here
+// would need the Sigstore libraries and a transparency log lookup, so they are not supported: they are rejected
+// with an error saying so.
+type SignaturePolicy struct {
+	// emails of the identities allowed to sign. An entry starting with "@", e.g. "@solo.io", allows any email
+	// in that domain. If empty, any verified signature is trusted.
+	AllowedSigners []string
+	// if set, a release tag must be an annotated tag with a verified signature, in addition to the commit it points to
+	RequireSignedTag bool
+}
+
+const sigstoreSignatureArmor = "-----BEGIN SIGNED MESSAGE-----"
+
+type errorUnverifiedSignature struct {
+	object, sha, reason string
+}
+
+func (e *errorUnverifiedSignature) Error() string {
+	return fmt.Sprintf("The signature of %s %s could not be verified (%s). "+
+		"Releases may only be created from signed commits", e.object, e.sha, e.reason)
+}
+
+func IsUnverifiedSignatureError(err error) bool {
+	_, ok := err.(*errorUnverifiedSignature)
+	return ok
+}
+
+type errorUntrustedSigner struct {
+	object, sha, signer string
+}
+
+func (e *errorUntrustedSigner) Error() string {
+	return fmt.Sprintf("%s %s is signed by %s, who is not an allowed signer", e.object, e.sha, e.signer)
+}
+
+func IsUntrustedSignerError(err error) bool {
+	_, ok := err.(*errorUntrustedSigner)
+	return ok
+}
+
+// VerifyCommitSignature returns an error if the commit is not signed by an identity the policy trusts.
+// The error can be checked with IsUnverifiedSignatureError and IsUntrustedSignerError.
+func VerifyCommitSignature(ctx context.Context, client *github.Client, owner, repo, sha string, policy SignaturePolicy) error {
+	commit, _, err := client.Git.GetCommit(ctx, owner, repo, sha)
+	if err != nil {
+		contextutils.LoggerFrom(ctx).Errorw("Unable to get commit", zap.Error(err), zap.String("sha", sha))
+		return err
+	}
+	return policy.check("commit", sha, commit.GetVerification(), commit.GetCommitter().GetEmail())
+}
+
+// VerifyReleaseSignature returns an error if the commit tag points to, or the tag itself when
+// policy.RequireSignedTag is set, is not signed by an identity the policy trusts. Release automation
+// should call it before publishing a release for tag.
+func VerifyReleaseSignature(ctx context.Context, client *github.Client, owner, repo, tag string, policy SignaturePolicy) error {
+	ref, _, err := client.Git.GetRef(ctx, owner, repo, "tags/"+tag)
+	if err != nil {
+		contextutils.LoggerFrom(ctx).Errorw("Unable to get tag", zap.Error(err), zap.String("tag", tag))
+		return err
+	}
+	sha := ref.GetObject().GetSHA()
+	if ref.GetObject().GetType() == "tag" {
+		// annotated tags point to a tag object, which in turn points to the commit
+		tagObject, _, err := client.Git.GetTag(ctx, owner, repo, sha)
+		if err != nil {
+			contextutils.LoggerFrom(ctx).Errorw("Unable to get tag object", zap.Error(err), zap.String("tag", tag))
+			return err
+		}
+		if policy.RequireSignedTag {
+			if err := policy.check("tag", tag, tagObject.GetVerification(), tagObject.GetTagger().GetEmail()); err != nil {
+				return err
+			}
+		}
+		sha = tagObject.GetObject().GetSHA()
+	} else if policy.RequireSignedTag {
+		return &errorUnverifiedSignature{object: "tag", sha: tag, reason: "lightweight tags can't be signed"}
+	}
+	return VerifyCommitSignature(ctx, client, owner, repo, sha, policy)
+}
+
+func (p SignaturePolicy) check(object, sha string, verification *github.SignatureVerification, signer string) error {
+	if !verification.GetVerified() {
+		reason := verification.GetReason()
+		if reason == "" {
+			reason = "unsigned"
+		}
+		// gitsign writes X.509 signatures in this armor, which GitHub only verifies for S/MIME certificates it knows
+		if strings.Contains(verification.GetSignature(), sigstoreSignatureArmor) {
+			reason = "Sigstore signatures are not supported, sign with a GPG, SSH or S/MIME key registered with GitHub"
+		}
+		return &errorUnverifiedSignature{object: object, sha: sha, reason: reason}
+	}
+	if len(p.AllowedSigners) == 0 {
+		return nil
+	}
+	signer = strings.ToLower(signer)
+	for _, allowed := range p.AllowedSigners {
+		allowed = strings.ToLower(allowed)
+		if signer == allowed || strings.HasPrefix(allowed, "@") && strings.HasSuffix(signer, allowed) {
+			return nil
+		}
+	}
+	return &errorUntrustedSigner{object: object, sha: sha, signer: signer}
+}
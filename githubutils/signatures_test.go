@@ -0,0 +1,93 @@
+package githubutils_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/google/go-github/v32/github"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/solo-io/go-utils/githubutils"
+)
+
+var _ = Describe("signature verification", func() {
+	var (
+		ctx    = context.Background()
+		server *httptest.Server
+		client *github.Client
+		policy githubutils.SignaturePolicy
+	)
+
+	BeforeEach(func() {
+		policy = githubutils.SignaturePolicy{AllowedSigners: []string{"release-bot@example.com", "@solo.io"}}
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/solo-io/testrepo/git/commits/signed", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"sha": "signed", "committer": {"email": "dev@solo.io"}, "verification": {"verified": true, "reason": "valid"}}`)
+		})
+		mux.HandleFunc("/repos/solo-io/testrepo/git/commits/outsider", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"sha": "outsider", "committer": {"email": "someone@example.org"}, "verification": {"verified": true, "reason": "valid"}}`)
+		})
+		mux.HandleFunc("/repos/solo-io/testrepo/git/commits/unsigned", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"sha": "unsigned", "committer": {"email": "dev@solo.io"}, "verification": {"verified": false, "reason": "unsigned"}}`)
+		})
+		mux.HandleFunc("/repos/solo-io/testrepo/git/commits/gitsign", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"sha": "gitsign", "committer": {"email": "dev@solo.io"}, "verification": {"verified": false, "reason": "bad_cert", `+
+				`"signature": "-----BEGIN SIGNED MESSAGE-----\nMIIEOQYJKoZIhvcNAQcCoIIEKjCCBCYCAQExDTALBglghkgBZQMEAgEwCwYJKoZI\n-----END SIGNED MESSAGE-----\n"}}`)
+		})
+		mux.HandleFunc("/repos/solo-io/testrepo/git/ref/tags/v1.0.0", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"ref": "refs/tags/v1.0.0", "object": {"type": "tag", "sha": "tagobject"}}`)
+		})
+		mux.HandleFunc("/repos/solo-io/testrepo/git/tags/tagobject", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"tag": "v1.0.0", "tagger": {"email": "release-bot@example.com"}, "object": {"type": "commit", "sha": "signed"}, "verification": {"verified": true, "reason": "valid"}}`)
+		})
+		mux.HandleFunc("/repos/solo-io/testrepo/git/ref/tags/v1.0.1", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"ref": "refs/tags/v1.0.1", "object": {"type": "commit", "sha": "signed"}}`)
+		})
+		server = httptest.NewServer(mux)
+		client = github.NewClient(nil)
+		client.BaseURL, _ = url.Parse(server.URL + "/")
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("accepts a commit signed by an allowed signer", func() {
+		Expect(githubutils.VerifyCommitSignature(ctx, client, "solo-io", "testrepo", "signed", policy)).To(Succeed())
+	})
+
+	It("rejects an unsigned commit", func() {
+		err := githubutils.VerifyCommitSignature(ctx, client, "solo-io", "testrepo", "unsigned", policy)
+		Expect(githubutils.IsUnverifiedSignatureError(err)).To(BeTrue())
+	})
+
+	It("rejects Sigstore signatures as unsupported", func() {
+		err := githubutils.VerifyCommitSignature(ctx, client, "solo-io", "testrepo", "gitsign", policy)
+		Expect(githubutils.IsUnverifiedSignatureError(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("Sigstore signatures are not supported")))
+	})
+
+	It("rejects a commit signed by someone else", func() {
+		err := githubutils.VerifyCommitSignature(ctx, client, "solo-io", "testrepo", "outsider", policy)
+		Expect(githubutils.IsUntrustedSignerError(err)).To(BeTrue())
+
+		policy.AllowedSigners = nil
+		Expect(githubutils.VerifyCommitSignature(ctx, client, "solo-io", "testrepo", "outsider", policy)).To(Succeed())
+	})
+
+	It("verifies the tag and the commit it points to", func() {
+		policy.RequireSignedTag = true
+		Expect(githubutils.VerifyReleaseSignature(ctx, client, "solo-io", "testrepo", "v1.0.0", policy)).To(Succeed())
+	})
+
+	It("rejects lightweight tags when signed tags are required", func() {
+		Expect(githubutils.VerifyReleaseSignature(ctx, client, "solo-io", "testrepo", "v1.0.1", policy)).To(Succeed())
+
+		policy.RequireSignedTag = true
+		err := githubutils.VerifyReleaseSignature(ctx, client, "solo-io", "testrepo", "v1.0.1", policy)
+		Expect(githubutils.IsUnverifiedSignatureError(err)).To(BeTrue())
+	})
+})
@@ -0,0 +1,190 @@
+package protoutils
+
+import (
+	"math/rand"
+	"reflect"
+	"sync"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/rotisserie/eris"
+)
+
+var (
+	UnregisteredTypeError = func(msg proto.Message) error {
+		return eris.Errorf("no conversion registered for type %T", msg)
+	}
+
+	IncompatibleTypesError = func(src, dst proto.Message) error {
+		return eris.Errorf("cannot convert %T to %T, they do not share a hub version", src, dst)
+	}
+
+	UnexpectedConversionResultError = func(expected, actual proto.Message) error {
+		return eris.Errorf("conversion to %T returned a %T", expected, actual)
+	}
+
+	RoundTripMismatchError = func(via proto.Message, original, roundTripped proto.Message) error {
+		return eris.Errorf("round trip through %T changed the message:\noriginal: %v\nround tripped: %v", via, original, roundTripped)
+	}
+)
+
+// ConvertFunc converts a message of one version of an API into another version
+type ConvertFunc func(src proto.Message) (proto.Message, error)
+
+// ConverterRegistry converts between versions of an API using the hub-and-spoke model: one version of each
+// type acts as the hub, and every other version (a spoke) only registers conversions to and from the hub.
+// Converting between two spokes goes through the hub, so n versions need 2(n-1) conversion functions
+// rather than n(n-1).
+type ConverterRegistry struct {
+	lock    sync.RWMutex
+	hubs    map[reflect.Type]reflect.Type
+	toHub   map[reflect.Type]ConvertFunc
+	fromHub map[reflect.Type]ConvertFunc
+}
+
+func NewConverterRegistry() *ConverterRegistry {
+	return &ConverterRegistry{
+		hubs:    map[reflect.Type]reflect.Type{},
+		toHub:   map[reflect.Type]ConvertFunc{},
+		fromHub: map[reflect.Type]ConvertFunc{},
+	}
+}
+
+// RegisterSpoke registers the conversions between a spoke version and its hub version.
+// spoke and hub are only used for their types, e.g. RegisterSpoke(&v1.Upstream{}, &v2.Upstream{}, ...)
+func (r *ConverterRegistry) RegisterSpoke(spoke, hub proto.Message, toHub, fromHub ConvertFunc) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	hubType := reflect.TypeOf(hub)
+	spokeType := reflect.TypeOf(spoke)
+	r.hubs[hubType] = hubType
+	r.hubs[spokeType] = hubType
+	r.toHub[spokeType] = toHub
+	r.fromHub[spokeType] = fromHub
+}
+
+// Convert converts src into dst, which must be a version of the same type registered with the same hub.
+// dst is reset before the result is written into it. The conversion functions may call Convert themselves,
+// e.g. for nested messages.
+func (r *ConverterRegistry) Convert(src, dst proto.Message) error {
+	srcType, dstType := reflect.TypeOf(src), reflect.TypeOf(dst)
+	if srcType == dstType {
+		dst.Reset()
+		proto.Merge(dst, src)
+		return nil
+	}
+	toHub, fromHub, err := r.conversions(src, dst)
+	if err != nil {
+		return err
+	}
+
+	hub := src
+	if toHub != nil {
+		if hub, err = toHub(src); err != nil {
+			return err
+		}
+	}
+	result := hub
+	if fromHub != nil {
+		if result, err = fromHub(hub); err != nil {
+			return err
+		}
+	}
+	if reflect.TypeOf(result) != dstType {
+		return UnexpectedConversionResultError(dst, result)
+	}
+	dst.Reset()
+	proto.Merge(dst, result)
+	return nil
+}
+
+// conversions returns the functions converting src to the hub and the hub to dst, nil when src or dst is the
+// hub. They are called without the lock held, so that they can call Convert while a spoke is being registered.
+func (r *ConverterRegistry) conversions(src, dst proto.Message) (ConvertFunc, ConvertFunc, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	srcType, dstType := reflect.TypeOf(src), reflect.TypeOf(dst)
+	srcHub, ok := r.hubs[srcType]
+	if !ok {
+		return nil, nil, UnregisteredTypeError(src)
+	}
+	dstHub, ok := r.hubs[dstType]
+	if !ok {
+		return nil, nil, UnregisteredTypeError(dst)
+	}
+	if srcHub != dstHub {
+		return nil, nil, IncompatibleTypesError(src, dst)
+	}
+	var toHub, fromHub ConvertFunc
+	if srcType != srcHub {
+		toHub = r.toHub[srcType]
+	}
+	if dstType != dstHub {
+		fromHub = r.fromHub[dstType]
+	}
+	return toHub, fromHub, nil
+}
+
+// ConvertList converts every message in src to the type of example
+func (r *ConverterRegistry) ConvertList(src []proto.Message, example proto.Message) ([]proto.Message, error) {
+	if src == nil {
+		return nil, nil
+	}
+	result := make([]proto.Message, 0, len(src))
+	for _, msg := range src {
+		dst := newLike(example)
+		if err := r.Convert(msg, dst); err != nil {
+			return nil, err
+		}
+		result = append(result, dst)
+	}
+	return result, nil
+}
+
+// ConvertMap converts every value in src to the type of example
+func (r *ConverterRegistry) ConvertMap(src map[string]proto.Message, example proto.Message) (map[string]proto.Message, error) {
+	if src == nil {
+		return nil, nil
+	}
+	result := make(map[string]proto.Message, len(src))
+	for key, msg := range src {
+		dst := newLike(example)
+		if err := r.Convert(msg, dst); err != nil {
+			return nil, eris.Wrapf(err, "converting key %s", key)
+		}
+		result[key] = dst
+	}
+	return result, nil
+}
+
+// CheckRoundTrip converts src to the type of via and back, returning an error if the result differs from src.
+// Conversions that lose information are the most common bug when adding a new API version.
+func (r *ConverterRegistry) CheckRoundTrip(src, via proto.Message) error {
+	intermediate := newLike(via)
+	if err := r.Convert(src, intermediate); err != nil {
+		return err
+	}
+	back := newLike(src)
+	if err := r.Convert(intermediate, back); err != nil {
+		return err
+	}
+	if !proto.Equal(src, back) {
+		return RoundTripMismatchError(via, src, back)
+	}
+	return nil
+}
+
+// FuzzRoundTrip calls CheckRoundTrip on iterations messages built by generate. The seed is included in
+// the error so that a failure can be reproduced by calling FuzzRoundTrip again with the same seed.
+func (r *ConverterRegistry) FuzzRoundTrip(generate func(rnd *rand.Rand) proto.Message, via proto.Message, iterations int, seed int64) error {
+	rnd := rand.New(rand.NewSource(seed))
+	for i := 0; i < iterations; i++ {
+		if err := r.CheckRoundTrip(generate(rnd), via); err != nil {
+			return eris.Wrapf(err, "iteration %d with seed %d", i, seed)
+		}
+	}
+	return nil
+}
+
+func newLike(example proto.Message) proto.Message {
+	return reflect.New(reflect.TypeOf(example).Elem()).Interface().(proto.Message)
+}
@@ -0,0 +1,102 @@
+package protoutils_test
+
+import (
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/solo-io/go-utils/protoutils"
+)
+
+var _ = Describe("ConverterRegistry", func() {
+	// StringValue is the hub, Int64Value and BytesValue are spokes
+	var registry *ConverterRegistry
+
+	BeforeEach(func() {
+		registry = NewConverterRegistry()
+		registry.RegisterSpoke(&types.Int64Value{}, &types.StringValue{},
+			func(src proto.Message) (proto.Message, error) {
+				return &types.StringValue{Value: strconv.FormatInt(src.(*types.Int64Value).Value, 10)}, nil
+			},
+			func(src proto.Message) (proto.Message, error) {
+				i, err := strconv.ParseInt(src.(*types.StringValue).Value, 10, 64)
+				return &types.Int64Value{Value: i}, err
+			})
+		registry.RegisterSpoke(&types.BytesValue{}, &types.StringValue{},
+			func(src proto.Message) (proto.Message, error) {
+				return &types.StringValue{Value: string(src.(*types.BytesValue).Value)}, nil
+			},
+			func(src proto.Message) (proto.Message, error) {
+				return &types.BytesValue{Value: []byte(src.(*types.StringValue).Value)}, nil
+			})
+	})
+
+	It("converts between spokes through the hub", func() {
+		dst := &types.BytesValue{}
+		Expect(registry.Convert(&types.Int64Value{Value: 42}, dst)).NotTo(HaveOccurred())
+		Expect(dst.Value).To(Equal([]byte("42")))
+	})
+
+	It("converts lists and maps", func() {
+		list, err := registry.ConvertList([]proto.Message{&types.Int64Value{Value: 1}, &types.Int64Value{Value: 2}}, &types.StringValue{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list).To(Equal([]proto.Message{&types.StringValue{Value: "1"}, &types.StringValue{Value: "2"}}))
+
+		m, err := registry.ConvertMap(map[string]proto.Message{"a": &types.StringValue{Value: "3"}}, &types.Int64Value{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(m).To(Equal(map[string]proto.Message{"a": &types.Int64Value{Value: 3}}))
+	})
+
+	It("rejects types that are not registered", func() {
+		err := registry.Convert(&types.Int64Value{Value: 1}, &types.BoolValue{})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("detects lossy round trips", func() {
+		Expect(registry.CheckRoundTrip(&types.Int64Value{Value: 7}, &types.BytesValue{})).NotTo(HaveOccurred())
+		err := registry.CheckRoundTrip(&types.StringValue{Value: "not a number"}, &types.Int64Value{})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("fuzzes round trips reporting the seed", func() {
+		err := registry.FuzzRoundTrip(func(rnd *rand.Rand) proto.Message {
+			return &types.Int64Value{Value: rnd.Int63()}
+		}, &types.BytesValue{}, 100, 1234)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = registry.FuzzRoundTrip(func(rnd *rand.Rand) proto.Message {
+			return &types.StringValue{Value: "x" + strconv.Itoa(rnd.Int())}
+		}, &types.Int64Value{}, 10, 1234)
+		Expect(err).To(MatchError(ContainSubstring("seed 1234")))
+	})
+	It("lets conversions convert nested messages while spokes are registered", func() {
+		// converts a DoubleValue by converting its integer part as an Int64Value
+		registry.RegisterSpoke(&types.DoubleValue{}, &types.StringValue{},
+			func(src proto.Message) (proto.Message, error) {
+				registered := make(chan struct{})
+				go func() {
+					defer close(registered)
+					registry.RegisterSpoke(&types.FloatValue{}, &types.StringValue{}, nil, nil)
+				}()
+				// give the registration time to wait for the lock
+				time.Sleep(10 * time.Millisecond)
+				nested := &types.StringValue{}
+				if err := registry.Convert(&types.Int64Value{Value: int64(src.(*types.DoubleValue).Value)}, nested); err != nil {
+					return nil, err
+				}
+				<-registered
+				return nested, nil
+			}, nil)
+
+		converted := make(chan error)
+		go func() {
+			converted <- registry.Convert(&types.DoubleValue{Value: 4.5}, &types.StringValue{})
+		}()
+		Eventually(converted).Should(Receive(BeNil()))
+	})
+})
@@ -0,0 +1,147 @@
+package protoutils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gogo/protobuf/proto"
+)
+
+// TruncationMarkerKey is the key MarshalForLog adds to objects it omitted fields from
+const TruncationMarkerKey = "_truncated"
+
+// the most a truncation marker takes up, e.g. `,"_truncated":"999999 of 999999 fields omitted"`
+const truncationMarkerReserve = 56
+
+// MarshalForLog returns msg as JSON of at most maxBytes, so that logging a large snapshot doesn't exceed the
+// line limits of log pipelines. If the JSON is larger, whole fields are omitted, and objects that had fields
+// omitted say so in a "_truncated" field; arrays end in a string saying how many elements were omitted.
+// Nested objects and arrays are truncated rather than omitted if part of them fits.
+// maxBytes should leave room for at least one marker, i.e. be larger than 64.
+func MarshalForLog(msg proto.Message, maxBytes int) string {
+	data, err := MarshalBytes(msg)
+	if err != nil {
+		return fmt.Sprintf(`{%q:"unable to marshal %T: %v"}`, TruncationMarkerKey, msg, err)
+	}
+	if len(data) <= maxBytes {
+		return string(data)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	node, err := parseJSONNode(dec)
+	if err != nil {
+		return fmt.Sprintf(`{%q:"unable to parse %T: %v"}`, TruncationMarkerKey, msg, err)
+	}
+	if truncated, ok := node.render(maxBytes); ok {
+		return truncated
+	}
+	return fmt.Sprintf(`{%q:"%d bytes omitted"}`, TruncationMarkerKey, len(data))
+}
+
+// jsonNode keeps the order of object fields, which jsonpb writes in the order the message defines them
+type jsonNode struct {
+	// '{', '[', or 0 for scalars
+	kind     json.Delim
+	raw      []byte
+	keys     []string
+	children []*jsonNode
+}
+
+func parseJSONNode(dec *json.Decoder) (*jsonNode, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := token.(json.Delim)
+	if !ok {
+		raw, err := json.Marshal(token)
+		return &jsonNode{raw: raw}, err
+	}
+	node := &jsonNode{kind: delim}
+	for dec.More() {
+		if delim == '{' {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			node.keys = append(node.keys, key.(string))
+		}
+		child, err := parseJSONNode(dec)
+		if err != nil {
+			return nil, err
+		}
+		node.children = append(node.children, child)
+	}
+	// the closing delimiter
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// render returns the node as JSON of at most budget bytes, or false if it can't be made to fit
+func (n *jsonNode) render(budget int) (string, bool) {
+	if n.kind == 0 {
+		return string(n.raw), len(n.raw) <= budget
+	}
+	if full := n.String(); len(full) <= budget {
+		return full, true
+	}
+	// room for the braces and the marker
+	available := budget - 2 - truncationMarkerReserve
+	if available < 0 {
+		return "", false
+	}
+	var parts []string
+	used, omitted := 0, 0
+	for i, child := range n.children {
+		prefix := ""
+		if n.kind == '{' {
+			key, _ := json.Marshal(n.keys[i])
+			prefix = string(key) + ":"
+		}
+		separator := 0
+		if len(parts) > 0 {
+			separator = 1
+		}
+		rendered, ok := child.render(available - used - separator - len(prefix))
+		if !ok || (n.kind == '[' && omitted > 0) {
+			// arrays keep a prefix of their elements, so the marker can say how many are missing at the end
+			omitted++
+			continue
+		}
+		parts = append(parts, prefix+rendered)
+		used += separator + len(prefix) + len(rendered)
+	}
+	if omitted > 0 {
+		if n.kind == '{' {
+			parts = append(parts, fmt.Sprintf(`%q:"%d of %d fields omitted"`, TruncationMarkerKey, omitted, len(n.children)))
+		} else {
+			parts = append(parts, fmt.Sprintf(`"...%d more elements"`, omitted))
+		}
+	}
+	if n.kind == '{' {
+		return "{" + strings.Join(parts, ",") + "}", true
+	}
+	return "[" + strings.Join(parts, ",") + "]", true
+}
+
+func (n *jsonNode) String() string {
+	if n.kind == 0 {
+		return string(n.raw)
+	}
+	parts := make([]string, len(n.children))
+	for i, child := range n.children {
+		parts[i] = child.String()
+		if n.kind == '{' {
+			key, _ := json.Marshal(n.keys[i])
+			parts[i] = string(key) + ":" + parts[i]
+		}
+	}
+	if n.kind == '{' {
+		return "{" + strings.Join(parts, ",") + "}"
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
@@ -0,0 +1,64 @@
+package protoutils_test
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/gogo/protobuf/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/solo-io/go-utils/protoutils"
+)
+
+var _ = Describe("MarshalForLog", func() {
+
+	stringValue := func(s string) *types.Value {
+		return &types.Value{Kind: &types.Value_StringValue{StringValue: s}}
+	}
+
+	It("returns small messages as they are", func() {
+		msg := &types.Struct{Fields: map[string]*types.Value{"a": stringValue("b")}}
+		Expect(MarshalForLog(msg, 1024)).To(Equal(`{"a":"b"}`))
+	})
+
+	It("omits whole fields that don't fit and says so", func() {
+		msg := &types.Struct{Fields: map[string]*types.Value{
+			"a": stringValue("small"),
+			"b": stringValue(strings.Repeat("x", 500)),
+			"c": stringValue("also small"),
+		}}
+		out := MarshalForLog(msg, 200)
+		Expect(len(out)).To(BeNumerically("<=", 200))
+		var parsed map[string]interface{}
+		Expect(json.Unmarshal([]byte(out), &parsed)).To(Succeed())
+		Expect(parsed).To(Equal(map[string]interface{}{
+			"a":                 "small",
+			"c":                 "also small",
+			TruncationMarkerKey: "1 of 3 fields omitted",
+		}))
+	})
+
+	It("truncates nested lists to the elements that fit", func() {
+		var values []*types.Value
+		for i := 0; i < 100; i++ {
+			values = append(values, stringValue("element"))
+		}
+		msg := &types.Struct{Fields: map[string]*types.Value{
+			"list": {Kind: &types.Value_ListValue{ListValue: &types.ListValue{Values: values}}},
+		}}
+		out := MarshalForLog(msg, 300)
+		Expect(len(out)).To(BeNumerically("<=", 300))
+		var parsed map[string][]string
+		Expect(json.Unmarshal([]byte(out), &parsed)).To(Succeed())
+		list := parsed["list"]
+		Expect(len(list)).To(BeNumerically(">", 10))
+		Expect(list[0]).To(Equal("element"))
+		Expect(list[len(list)-1]).To(MatchRegexp(`^\.\.\.\d+ more elements$`))
+	})
+
+	It("marks messages that can't fit at all", func() {
+		msg := &types.Struct{Fields: map[string]*types.Value{"a": stringValue(strings.Repeat("x", 500))}}
+		Expect(MarshalForLog(msg, 10)).To(MatchRegexp(`^\{"_truncated":"\d+ bytes omitted"\}$`))
+	})
+})
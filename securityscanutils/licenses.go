@@ -0,0 +1,239 @@
+package securityscanutils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	"github.com/rotisserie/eris"
+	"github.com/solo-io/go-utils/contextutils"
+	"github.com/solo-io/go-utils/stringutils"
+)
+
+const UnknownLicense = "Unknown"
+
+var (
+	FailedToListModulesError = func(err error, output string) error {
+		return errors.Wrapf(err, "failed to list go modules: %s", output)
+	}
+
+	FailedToReadPolicyError = func(err error, path string) error {
+		return errors.Wrapf(err, "failed to read license policy file: %s", path)
+	}
+
+	LicenseViolationsError = func(count int) error {
+		return eris.Errorf("found %d module(s) with licenses that violate the license policy", count)
+	}
+)
+
+// LicensePolicy decides which licenses dependencies may use.
+// A license in Denied is always a violation. If Allowed is non-empty, any license not in it
+// (including UnknownLicense) is a violation as well. Modules listed in Exceptions are never violations.
+type LicensePolicy struct {
+	Allowed []string `json:"allowed,omitempty"`
+	Denied  []string `json:"denied,omitempty"`
+	// module path -> justification
+	Exceptions map[string]string `json:"exceptions,omitempty"`
+}
+
+type ModuleLicense struct {
+	Path    string
+	Version string
+	License string
+	// why the license violates the policy, empty if it does not
+	Violation string
+}
+
+type LicenseReport struct {
+	Modules []ModuleLicense
+}
+
+// ReadLicensePolicy reads a yaml policy file, for example:
+//
+//   allowed: [Apache-2.0, MIT, BSD-3-Clause]
+//   denied: [AGPL-3.0]
+//   exceptions:
+//     github.com/some/module: approved by legal, see issue 123
+func ReadLicensePolicy(path string) (*LicensePolicy, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, FailedToReadPolicyError(err, path)
+	}
+	var policy LicensePolicy
+	if err := yaml.Unmarshal(b, &policy); err != nil {
+		return nil, FailedToReadPolicyError(err, path)
+	}
+	return &policy, nil
+}
+
+// ScanModuleLicenses inventories every module dependency of the go module in moduleDir and the license it
+// is distributed under. It returns the report along with an error if any module violates the policy.
+// Modules must be present in the module cache (e.g. after `go mod download`) for their license to be detected.
+func ScanModuleLicenses(ctx context.Context, moduleDir string, policy LicensePolicy) (*LicenseReport, error) {
+	modules, err := listModules(moduleDir)
+	if err != nil {
+		return nil, err
+	}
+	report := &LicenseReport{}
+	violations := 0
+	for _, mod := range modules {
+		if mod.Main {
+			continue
+		}
+		dir := mod.Dir
+		if mod.Replace != nil {
+			mod = *mod.Replace
+			if mod.Dir != "" {
+				dir = mod.Dir
+			}
+		}
+		license := UnknownLicense
+		if dir != "" {
+			license = DetectLicense(dir)
+		} else {
+			contextutils.LoggerFrom(ctx).Warnf("module %s@%s is not in the module cache, cannot detect its license", mod.Path, mod.Version)
+		}
+		result := ModuleLicense{
+			Path:      mod.Path,
+			Version:   mod.Version,
+			License:   license,
+			Violation: policy.violation(mod.Path, license),
+		}
+		if result.Violation != "" {
+			violations++
+		}
+		report.Modules = append(report.Modules, result)
+	}
+	sort.Slice(report.Modules, func(i, j int) bool {
+		return report.Modules[i].Path < report.Modules[j].Path
+	})
+	if violations > 0 {
+		return report, LicenseViolationsError(violations)
+	}
+	return report, nil
+}
+
+func (p LicensePolicy) violation(module, license string) string {
+	if _, ok := p.Exceptions[module]; ok {
+		return ""
+	}
+	if stringutils.ContainsString(license, p.Denied) {
+		return fmt.Sprintf("license %s is denied", license)
+	}
+	if len(p.Allowed) > 0 && !stringutils.ContainsString(license, p.Allowed) {
+		return fmt.Sprintf("license %s is not in the allowed list", license)
+	}
+	return ""
+}
+
+// Violations returns only the modules that violate the policy
+func (r *LicenseReport) Violations() []ModuleLicense {
+	var violations []ModuleLicense
+	for _, mod := range r.Modules {
+		if mod.Violation != "" {
+			violations = append(violations, mod)
+		}
+	}
+	return violations
+}
+
+// Print writes the report as a table
+func (r *LicenseReport) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "MODULE\tVERSION\tLICENSE\tVIOLATION")
+	for _, mod := range r.Modules {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", mod.Path, mod.Version, mod.License, mod.Violation)
+	}
+	return tw.Flush()
+}
+
+type goModule struct {
+	Path    string
+	Version string
+	Dir     string
+	Main    bool
+	Replace *goModule
+}
+
+func listModules(moduleDir string) ([]goModule, error) {
+	cmd := exec.Command("go", "list", "-m", "-json", "all")
+	cmd.Dir = moduleDir
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return nil, FailedToListModulesError(err, stderr.String())
+	}
+	// the output is a stream of json objects, not an array
+	var modules []goModule
+	decoder := json.NewDecoder(stdout)
+	for decoder.More() {
+		var mod goModule
+		if err := decoder.Decode(&mod); err != nil {
+			return nil, FailedToListModulesError(err, stdout.String())
+		}
+		modules = append(modules, mod)
+	}
+	return modules, nil
+}
+
+var licenseFileNames = []string{"LICENSE", "LICENSE.md", "LICENSE.txt", "LICENCE", "LICENCE.md", "COPYING", "COPYING.md", "LICENSE-2.0.txt"}
+
+// each license is identified by all of its markers appearing in the license file.
+// more specific licenses must come before the licenses they would otherwise be mistaken for.
+var licenseMarkers = []struct {
+	license string
+	markers []string
+}{
+	{"AGPL-3.0", []string{"GNU AFFERO GENERAL PUBLIC LICENSE"}},
+	{"LGPL-3.0", []string{"GNU LESSER GENERAL PUBLIC LICENSE", "Version 3"}},
+	{"LGPL-2.1", []string{"GNU LESSER GENERAL PUBLIC LICENSE"}},
+	{"GPL-3.0", []string{"GNU GENERAL PUBLIC LICENSE", "Version 3"}},
+	{"GPL-2.0", []string{"GNU GENERAL PUBLIC LICENSE"}},
+	{"MPL-2.0", []string{"Mozilla Public License", "2.0"}},
+	{"Apache-2.0", []string{"Apache License", "Version 2.0"}},
+	{"MIT", []string{"Permission is hereby granted, free of charge"}},
+	{"ISC", []string{"Permission to use, copy, modify, and/or distribute this software for any"}},
+	{"BSD-3-Clause", []string{"Redistribution and use in source and binary forms", "Neither the name"}},
+	{"BSD-2-Clause", []string{"Redistribution and use in source and binary forms"}},
+	{"Unlicense", []string{"This is free and unencumbered software released into the public domain"}},
+}
+
+// DetectLicense identifies the license of the module checked out in dir from its license file.
+// It returns UnknownLicense if there is no license file or it is not recognized.
+func DetectLicense(dir string) string {
+	for _, name := range licenseFileNames {
+		b, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		// normalize whitespace so that line wrapping doesn't matter
+		text := strings.Join(strings.Fields(string(b)), " ")
+		for _, candidate := range licenseMarkers {
+			if containsAll(text, candidate.markers) {
+				return candidate.license
+			}
+		}
+		return UnknownLicense
+	}
+	return UnknownLicense
+}
+
+func containsAll(text string, markers []string) bool {
+	for _, marker := range markers {
+		if !strings.Contains(text, marker) {
+			return false
+		}
+	}
+	return true
+}
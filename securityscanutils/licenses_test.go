@@ -0,0 +1,81 @@
+package securityscanutils_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/solo-io/go-utils/securityscanutils"
+)
+
+var _ = Describe("license scanning", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "licenses")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	writeLicense := func(name, text string) {
+		Expect(ioutil.WriteFile(filepath.Join(dir, name), []byte(text), 0644)).NotTo(HaveOccurred())
+	}
+
+	It("detects licenses regardless of line wrapping", func() {
+		writeLicense("LICENSE", "Apache License\n                           Version 2.0, January 2004")
+		Expect(securityscanutils.DetectLicense(dir)).To(Equal("Apache-2.0"))
+	})
+
+	It("tells BSD variants apart", func() {
+		writeLicense("LICENSE.md", "Redistribution and use in source and binary forms, with or without\nmodification... Neither the name of the copyright holder")
+		Expect(securityscanutils.DetectLicense(dir)).To(Equal("BSD-3-Clause"))
+	})
+
+	It("reports unknown licenses", func() {
+		Expect(securityscanutils.DetectLicense(dir)).To(Equal(securityscanutils.UnknownLicense))
+		writeLicense("COPYING", "all rights reserved")
+		Expect(securityscanutils.DetectLicense(dir)).To(Equal(securityscanutils.UnknownLicense))
+	})
+
+	It("reads policy files", func() {
+		writeLicense("policy.yaml", "allowed: [MIT]\ndenied: [AGPL-3.0]\nexceptions:\n  github.com/foo/bar: approved\n")
+		policy, err := securityscanutils.ReadLicensePolicy(filepath.Join(dir, "policy.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(policy.Allowed).To(Equal([]string{"MIT"}))
+		Expect(policy.Denied).To(Equal([]string{"AGPL-3.0"}))
+		Expect(policy.Exceptions).To(HaveKey("github.com/foo/bar"))
+	})
+
+	It("scans the dependencies of a go module", func() {
+		policy := securityscanutils.LicensePolicy{
+			Denied:     []string{"BSD-2-Clause"},
+			Exceptions: map[string]string{"github.com/pkg/errors": "test exception"},
+		}
+		report, err := securityscanutils.ScanModuleLicenses(context.Background(), "..", policy)
+		// other BSD-2-Clause dependencies may be present, so only check the excepted module
+		if err != nil {
+			Expect(report.Violations()).NotTo(BeEmpty())
+		}
+		var found bool
+		for _, mod := range report.Modules {
+			if mod.Path == "github.com/pkg/errors" {
+				found = true
+				Expect(mod.License).To(Equal("BSD-2-Clause"))
+				Expect(mod.Violation).To(BeEmpty())
+			}
+		}
+		Expect(found).To(BeTrue())
+
+		buf := &bytes.Buffer{}
+		Expect(report.Print(buf)).NotTo(HaveOccurred())
+		Expect(buf.String()).To(ContainSubstring("github.com/pkg/errors"))
+	})
+})
@@ -0,0 +1,13 @@
+package securityscanutils_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestSecurityScanUtils(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Security Scan Utils Suite")
+}
@@ -0,0 +1,253 @@
+package stats
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rotisserie/eris"
+)
+
+// ResourceSample is the resource usage of a process under test at a point in time
+type ResourceSample struct {
+	Time time.Time
+	// resident memory for /metrics sources, memory obtained from the OS for pprof sources
+	MemoryBytes float64
+	Goroutines  float64
+}
+
+// SampleFunc takes a single sample of a process's resource usage
+type SampleFunc func(ctx context.Context) (ResourceSample, error)
+
+var (
+	MissingMetricError = func(url, metric string) error {
+		return eris.Errorf("%s does not report %s", url, metric)
+	}
+	UnexpectedStatusError = func(url string, status int) error {
+		return eris.Errorf("GET %s returned status %d", url, status)
+	}
+)
+
+// MetricsSource samples a Prometheus /metrics endpoint exposing the standard Go collector metrics
+// (process_resident_memory_bytes and go_goroutines), e.g. http://localhost:9091/metrics
+func MetricsSource(url string) SampleFunc {
+	return func(ctx context.Context) (ResourceSample, error) {
+		values := map[string]float64{}
+		err := get(ctx, url, func(line string) {
+			fields := strings.Fields(line)
+			if len(fields) < 2 || strings.HasPrefix(line, "#") {
+				return
+			}
+			if value, err := strconv.ParseFloat(fields[1], 64); err == nil {
+				values[fields[0]] = value
+			}
+		})
+		if err != nil {
+			return ResourceSample{}, err
+		}
+		sample := ResourceSample{Time: time.Now()}
+		var ok bool
+		if sample.MemoryBytes, ok = values["process_resident_memory_bytes"]; !ok {
+			return ResourceSample{}, MissingMetricError(url, "process_resident_memory_bytes")
+		}
+		if sample.Goroutines, ok = values["go_goroutines"]; !ok {
+			return ResourceSample{}, MissingMetricError(url, "go_goroutines")
+		}
+		return sample, nil
+	}
+}
+
+// PprofSource samples a net/http/pprof endpoint, e.g. http://localhost:9091/debug/pprof
+func PprofSource(baseURL string) SampleFunc {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	return func(ctx context.Context) (ResourceSample, error) {
+		sample := ResourceSample{Time: time.Now(), Goroutines: -1, MemoryBytes: -1}
+		goroutineURL := baseURL + "/goroutine?debug=1"
+		err := get(ctx, goroutineURL, func(line string) {
+			// goroutine profile: total 42
+			if strings.HasPrefix(line, "goroutine profile: total ") {
+				sample.Goroutines, _ = strconv.ParseFloat(strings.TrimPrefix(line, "goroutine profile: total "), 64)
+			}
+		})
+		if err != nil {
+			return ResourceSample{}, err
+		}
+		heapURL := baseURL + "/heap?debug=1"
+		err = get(ctx, heapURL, func(line string) {
+			// # Sys = 72303880
+			if strings.HasPrefix(line, "# Sys = ") {
+				sample.MemoryBytes, _ = strconv.ParseFloat(strings.TrimPrefix(line, "# Sys = "), 64)
+			}
+		})
+		if err != nil {
+			return ResourceSample{}, err
+		}
+		if sample.Goroutines < 0 {
+			return ResourceSample{}, MissingMetricError(goroutineURL, "the goroutine total")
+		}
+		if sample.MemoryBytes < 0 {
+			return ResourceSample{}, MissingMetricError(heapURL, "Sys")
+		}
+		return sample, nil
+	}
+}
+
+func get(ctx context.Context, url string, line func(string)) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return UnexpectedStatusError(url, resp.StatusCode)
+	}
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		line(scanner.Text())
+	}
+	return scanner.Err()
+}
+
+// ResourceSampler collects samples of a process's resource usage over a test run, so that they can be
+// checked against a ResourceBudget once the run is over
+type ResourceSampler struct {
+	sample SampleFunc
+
+	lock    sync.Mutex
+	samples []ResourceSample
+}
+
+func NewResourceSampler(sample SampleFunc) *ResourceSampler {
+	return &ResourceSampler{sample: sample}
+}
+
+// Sample takes a single sample and records it
+func (s *ResourceSampler) Sample(ctx context.Context) (ResourceSample, error) {
+	sample, err := s.sample(ctx)
+	if err != nil {
+		return sample, err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.samples = append(s.samples, sample)
+	return sample, nil
+}
+
+// Run takes a sample every interval until ctx is done. Failed samples are skipped, since the process
+// may be briefly unavailable (e.g. during a restart); the budget check fails if there aren't enough samples.
+func (s *ResourceSampler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		s.Sample(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Samples returns the samples recorded so far
+func (s *ResourceSampler) Samples() []ResourceSample {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]ResourceSample(nil), s.samples...)
+}
+
+// ResourceBudget bounds the resource usage of a process over a test run. Growth is measured from the first
+// sample to the last. Zero values are not checked.
+type ResourceBudget struct {
+	MaxMemoryGrowthBytes float64
+	MaxGoroutineGrowth   float64
+	MaxGoroutines        float64
+}
+
+// Check returns an error if the samples exceed the budget. The error includes the trend of the samples,
+// see FormatTrend.
+func (b ResourceBudget) Check(samples []ResourceSample) error {
+	if len(samples) < 2 {
+		return eris.Errorf("at least 2 samples are needed to check a resource budget, got %d", len(samples))
+	}
+	first, last := samples[0], samples[len(samples)-1]
+	var violations []string
+	if growth := last.MemoryBytes - first.MemoryBytes; b.MaxMemoryGrowthBytes > 0 && growth > b.MaxMemoryGrowthBytes {
+		violations = append(violations, fmt.Sprintf("memory grew by %s, more than the budget of %s",
+			formatBytes(growth), formatBytes(b.MaxMemoryGrowthBytes)))
+	}
+	if growth := last.Goroutines - first.Goroutines; b.MaxGoroutineGrowth > 0 && growth > b.MaxGoroutineGrowth {
+		violations = append(violations, fmt.Sprintf("goroutines grew by %.0f, more than the budget of %.0f",
+			growth, b.MaxGoroutineGrowth))
+	}
+	if b.MaxGoroutines > 0 {
+		for _, sample := range samples {
+			if sample.Goroutines > b.MaxGoroutines {
+				violations = append(violations, fmt.Sprintf("%.0f goroutines at %s, more than the budget of %.0f",
+					sample.Goroutines, sample.Time.Format(time.RFC3339), b.MaxGoroutines))
+				break
+			}
+		}
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	trend := &strings.Builder{}
+	FormatTrend(trend, samples)
+	return eris.Errorf("resource budget exceeded:\n  %s\n\n%s", strings.Join(violations, "\n  "), trend.String())
+}
+
+const trendBarWidth = 30
+
+// FormatTrend writes a text chart of the samples, one row per sample, e.g.
+//
+//   time      memory                                  goroutines
+//   +0s        12.0MiB ###############                    40 ###############
+//   +10s       24.0MiB ##############################     80 ##############################
+func FormatTrend(w io.Writer, samples []ResourceSample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+	var maxMemory, maxGoroutines float64
+	for _, sample := range samples {
+		if sample.MemoryBytes > maxMemory {
+			maxMemory = sample.MemoryBytes
+		}
+		if sample.Goroutines > maxGoroutines {
+			maxGoroutines = sample.Goroutines
+		}
+	}
+	if _, err := fmt.Fprintf(w, "%-9s %-*s %s\n", "time", trendBarWidth+9, "memory", "goroutines"); err != nil {
+		return err
+	}
+	for _, sample := range samples {
+		offset := "+" + sample.Time.Sub(samples[0].Time).Round(time.Second).String()
+		if _, err := fmt.Fprintf(w, "%-9s %8s %-*s %6.0f %s\n", offset,
+			formatBytes(sample.MemoryBytes), trendBarWidth, bar(sample.MemoryBytes, maxMemory),
+			sample.Goroutines, bar(sample.Goroutines, maxGoroutines)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func bar(value, max float64) string {
+	if max <= 0 {
+		return ""
+	}
+	return strings.Repeat("#", int(value/max*trendBarWidth+0.5))
+}
+
+func formatBytes(b float64) string {
+	return fmt.Sprintf("%.1fMiB", b/(1024*1024))
+}
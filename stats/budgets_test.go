@@ -0,0 +1,95 @@
+package stats_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/solo-io/go-utils/stats"
+)
+
+var _ = Describe("Resource budgets", func() {
+
+	const mib = 1024 * 1024
+
+	var (
+		ctx        = context.Background()
+		server     *httptest.Server
+		memory     float64
+		goroutines int
+	)
+
+	BeforeEach(func() {
+		memory = 12 * mib
+		goroutines = 40
+		mux := http.NewServeMux()
+		mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "# HELP go_goroutines Number of goroutines that currently exist.\n"+
+				"# TYPE go_goroutines gauge\ngo_goroutines %d\nprocess_resident_memory_bytes %g\n", goroutines, memory)
+		})
+		mux.HandleFunc("/debug/pprof/goroutine", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "goroutine profile: total %d\n1 @ 0x43a0e5\n", goroutines)
+		})
+		mux.HandleFunc("/debug/pprof/heap", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "heap profile: 1: 2 [3: 4] @ heap/1048576\n\n# runtime.MemStats\n# Alloc = 100\n# Sys = %.0f\n", memory)
+		})
+		server = httptest.NewServer(mux)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("samples /metrics and pprof endpoints", func() {
+		sample, err := stats.MetricsSource(server.URL + "/metrics")(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sample.MemoryBytes).To(Equal(12.0 * mib))
+		Expect(sample.Goroutines).To(Equal(40.0))
+
+		sample, err = stats.PprofSource(server.URL + "/debug/pprof/")(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sample.MemoryBytes).To(Equal(12.0 * mib))
+		Expect(sample.Goroutines).To(Equal(40.0))
+	})
+
+	It("fails when metrics are missing", func() {
+		_, err := stats.MetricsSource(server.URL + "/debug/pprof/goroutine")(ctx)
+		Expect(err).To(MatchError(ContainSubstring("does not report process_resident_memory_bytes")))
+	})
+
+	It("checks samples against the budget", func() {
+		sampler := stats.NewResourceSampler(stats.MetricsSource(server.URL + "/metrics"))
+		_, err := sampler.Sample(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		memory, goroutines = 24*mib, 80
+		_, err = sampler.Sample(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(stats.ResourceBudget{MaxMemoryGrowthBytes: 16 * mib, MaxGoroutineGrowth: 50}.Check(sampler.Samples())).To(Succeed())
+
+		err = stats.ResourceBudget{MaxMemoryGrowthBytes: 8 * mib, MaxGoroutines: 60}.Check(sampler.Samples())
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("memory grew by 12.0MiB, more than the budget of 8.0MiB"))
+		Expect(err.Error()).To(ContainSubstring("80 goroutines at"))
+		Expect(err.Error()).To(ContainSubstring("##############################"))
+	})
+
+	It("formats the trend of the samples", func() {
+		start := time.Now()
+		samples := []stats.ResourceSample{
+			{Time: start, MemoryBytes: 12 * mib, Goroutines: 40},
+			{Time: start.Add(10 * time.Second), MemoryBytes: 24 * mib, Goroutines: 80},
+		}
+		out := &bytes.Buffer{}
+		Expect(stats.FormatTrend(out, samples)).To(Succeed())
+		Expect(out.String()).To(Equal(
+			"time      memory                                  goroutines\n" +
+				"+0s        12.0MiB ###############                    40 ###############\n" +
+				"+10s       24.0MiB ##############################     80 ##############################\n"))
+	})
+})
@@ -0,0 +1,148 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opencensus.io/trace"
+)
+
+// Span records how long a phase (e.g. "install") took. Spans started from a context that already
+// carries a span become its children, so a suite's phases form a tree that can be printed with
+// PrintSpanSummary once the suite is done.
+//
+// Every span is also an opencensus span, so it is exported by any registered opencensus trace
+// exporter (zpages, the OC agent/OTLP collector, ...), sampled with SpanSampler.
+type Span struct {
+	Name     string
+	Start    time.Time
+	Duration time.Duration
+
+	lock     sync.Mutex
+	ended    bool
+	children []*Span
+	ocSpan   *trace.Span
+}
+
+type spanKey struct{}
+
+// MaxRootSpans is how many root spans are kept for PrintSpanSummary. Once there are more, the oldest are
+// dropped, so that long-running processes such as bots don't use more and more memory. Spans are exported to
+// opencensus whether or not they are kept.
+var MaxRootSpans = 1000
+
+// SpanSampler decides which spans are exported to opencensus. Every span is by default, since there are few of
+// them compared to e.g. requests; set it to nil to use the sampler configured with trace.ApplyConfig instead,
+// which samples 1 in 10000 spans unless configured otherwise.
+var SpanSampler = trace.AlwaysSample()
+
+var (
+	rootSpansLock sync.Mutex
+	rootSpans     []*Span
+	droppedSpans  int
+)
+
+// StartSpan starts a span named name as a child of the span in ctx, if any.
+// The returned context carries the new span; call End on the span when the phase is over.
+func StartSpan(ctx context.Context, name string) (context.Context, *Span) {
+	var options []trace.StartOption
+	if SpanSampler != nil {
+		options = append(options, trace.WithSampler(SpanSampler))
+	}
+	ctx, ocSpan := trace.StartSpan(ctx, name, options...)
+	span := &Span{
+		Name:   name,
+		Start:  time.Now(),
+		ocSpan: ocSpan,
+	}
+	if parent, ok := ctx.Value(spanKey{}).(*Span); ok {
+		parent.lock.Lock()
+		parent.children = append(parent.children, span)
+		parent.lock.Unlock()
+	} else {
+		rootSpansLock.Lock()
+		rootSpans = append(rootSpans, span)
+		if excess := len(rootSpans) - MaxRootSpans; excess > 0 && MaxRootSpans > 0 {
+			// copy rather than reslice, so that the dropped spans can be garbage collected
+			rootSpans = append([]*Span(nil), rootSpans[excess:]...)
+			droppedSpans += excess
+		}
+		rootSpansLock.Unlock()
+	}
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// End records the span's duration. Calling End more than once has no effect.
+func (s *Span) End() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.ended {
+		return
+	}
+	s.ended = true
+	s.Duration = time.Since(s.Start)
+	s.ocSpan.End()
+}
+
+// Children returns the spans started from this span's context
+func (s *Span) Children() []*Span {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]*Span(nil), s.children...)
+}
+
+// PrintSpanSummary writes every span recorded so far as an indented tree, e.g.
+//
+//   install 1m2s
+//     helm install 45s
+//     wait for pods 17s
+//
+// Spans that have not ended yet are marked as running. If older root spans were dropped, see MaxRootSpans,
+// the summary starts by saying how many.
+func PrintSpanSummary(w io.Writer) error {
+	rootSpansLock.Lock()
+	roots := append([]*Span(nil), rootSpans...)
+	dropped := droppedSpans
+	rootSpansLock.Unlock()
+	if dropped > 0 {
+		if _, err := fmt.Fprintf(w, "(%d earlier spans dropped)\n", dropped); err != nil {
+			return err
+		}
+	}
+	for _, span := range roots {
+		if err := printSpan(w, span, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ResetSpans forgets all recorded spans, e.g. between suites
+func ResetSpans() {
+	rootSpansLock.Lock()
+	defer rootSpansLock.Unlock()
+	rootSpans = nil
+	droppedSpans = 0
+}
+
+func printSpan(w io.Writer, span *Span, depth int) error {
+	span.lock.Lock()
+	duration := span.Duration.Round(time.Millisecond).String()
+	if !span.ended {
+		duration = fmt.Sprintf("running for %s", time.Since(span.Start).Round(time.Millisecond))
+	}
+	span.lock.Unlock()
+	if _, err := fmt.Fprintf(w, "%s%s %s\n", strings.Repeat("  ", depth), span.Name, duration); err != nil {
+		return err
+	}
+	for _, child := range span.Children() {
+		if err := printSpan(w, child, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
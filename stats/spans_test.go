@@ -0,0 +1,82 @@
+package stats_test
+
+import (
+	"bytes"
+	"context"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/solo-io/go-utils/stats"
+	"go.opencensus.io/trace"
+)
+
+var _ = Describe("Spans", func() {
+
+	BeforeEach(func() {
+		stats.ResetSpans()
+	})
+
+	It("records spans hierarchically", func() {
+		ctx, install := stats.StartSpan(context.Background(), "install")
+		_, helm := stats.StartSpan(ctx, "helm install")
+		helm.End()
+		_, wait := stats.StartSpan(ctx, "wait for pods")
+		install.End()
+
+		Expect(install.Children()).To(Equal([]*stats.Span{helm, wait}))
+		Expect(install.Duration).To(BeNumerically(">=", helm.Duration))
+
+		buf := &bytes.Buffer{}
+		Expect(stats.PrintSpanSummary(buf)).NotTo(HaveOccurred())
+		Expect(buf.String()).To(MatchRegexp(`^install \S+\n  helm install \S+\n  wait for pods running for \S+\n$`))
+	})
+	It("keeps at most MaxRootSpans root spans", func() {
+		defer func(max int) { stats.MaxRootSpans = max }(stats.MaxRootSpans)
+		stats.MaxRootSpans = 2
+		for _, name := range []string{"first", "second", "third"} {
+			_, span := stats.StartSpan(context.Background(), name)
+			span.End()
+		}
+
+		buf := &bytes.Buffer{}
+		Expect(stats.PrintSpanSummary(buf)).NotTo(HaveOccurred())
+		Expect(buf.String()).To(MatchRegexp(`^\(1 earlier spans dropped\)\nsecond \S+\nthird \S+\n$`))
+
+		stats.ResetSpans()
+		buf.Reset()
+		Expect(stats.PrintSpanSummary(buf)).NotTo(HaveOccurred())
+		Expect(buf.String()).To(BeEmpty())
+	})
+	It("exports every span to opencensus", func() {
+		exporter := &recordingExporter{}
+		trace.RegisterExporter(exporter)
+		defer trace.UnregisterExporter(exporter)
+		for i := 0; i < 10; i++ {
+			_, span := stats.StartSpan(context.Background(), "install")
+			span.End()
+		}
+		Expect(exporter.names()).To(HaveLen(10))
+	})
+})
+
+type recordingExporter struct {
+	lock  sync.Mutex
+	spans []*trace.SpanData
+}
+
+func (e *recordingExporter) ExportSpan(span *trace.SpanData) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.spans = append(e.spans, span)
+}
+
+func (e *recordingExporter) names() []string {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	var names []string
+	for _, span := range e.spans {
+		names = append(names, span.Name)
+	}
+	return names
+}
@@ -0,0 +1,217 @@
+package exec
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/onsi/ginkgo"
+	"github.com/solo-io/go-utils/threadsafe"
+)
+
+// Command describes a command to run. Build one with Cmd and configure it with the chained setters, e.g.
+//
+//	out, err := exec.Cmd("kubectl", "get", "pods").Dir(dir).Env("KUBECONFIG=" + kubeconfig).Timeout(time.Minute).Output()
+type Command struct {
+	args    []string
+	dir     string
+	env     []string
+	stdin   io.Reader
+	timeout time.Duration
+	verbose bool
+	ctx     context.Context
+	stream  io.Writer
+	prefix  string
+	// nil unless set with DryRun, in which case it wins over SetDryRun and EXEC_DRY_RUN
+	dryRun *bool
+	// if clean is set, the command only inherits the variables in inherit from the current process
+	clean   bool
+	inherit []string
+	limits  *Limits
+}
+
+// Cmd returns a command that runs args[0] with the remaining args
+func Cmd(args ...string) *Command {
+	return &Command{
+		args: args,
+		ctx:  context.Background(),
+	}
+}
+
+// Dir sets the working directory, by default the current directory
+func (c *Command) Dir(dir string) *Command {
+	c.dir = dir
+	return c
+}
+
+// Env adds variables, in the form KEY=VALUE, to the environment of the current process for this command only.
+// Variables that are already set are overridden.
+func (c *Command) Env(env ...string) *Command {
+	c.env = append(c.env, env...)
+	return c
+}
+
+// CleanEnv starts the command with an empty environment instead of the environment of the current process,
+// only inheriting the variables named in keep, e.g. CleanEnv("PATH", "HOME"). Variables added with Env are
+// still set.
+func (c *Command) CleanEnv(keep ...string) *Command {
+	c.clean = true
+	c.inherit = append(c.inherit, keep...)
+	return c
+}
+
+// Stdin sets the command's input
+func (c *Command) Stdin(stdin io.Reader) *Command {
+	c.stdin = stdin
+	return c
+}
+
+// Timeout kills the command, along with any children it started, if it runs for longer than timeout
+func (c *Command) Timeout(timeout time.Duration) *Command {
+	c.timeout = timeout
+	return c
+}
+
+// Verbose also writes the command line, with sensitive flags masked, and the command's output to the GinkgoWriter
+func (c *Command) Verbose(verbose bool) *Command {
+	c.verbose = verbose
+	return c
+}
+
+// Context kills the command, along with any children it started, when ctx is done
+func (c *Command) Context(ctx context.Context) *Command {
+	c.ctx = ctx
+	return c
+}
+
+// Stream writes the command's output to out line by line as it is printed, with every line preceded by prefix
+func (c *Command) Stream(out io.Writer, prefix string) *Command {
+	c.stream = out
+	c.prefix = prefix
+	return c
+}
+
+// Run runs the command. If it fails, the error is a *CommandError including its output
+func (c *Command) Run() error {
+	_, err := c.Output()
+	return err
+}
+
+// Output runs the command and returns its stdout and stderr combined, in the order they were written
+func (c *Command) Output() (string, error) {
+	if c.isDryRun() {
+		c.logDryRun()
+		return "", nil
+	}
+	// a single writer for both streams preserves the order in which output was written
+	buf := &bytes.Buffer{}
+	cmd, flush, err := c.build(buf, buf)
+	if err != nil {
+		return "", err
+	}
+	err = c.runCmd(cmd)
+	flush()
+	if err != nil {
+		return "", newCommandError(cmd, err, buf.String())
+	}
+	return buf.String(), nil
+}
+
+// Result runs the command and returns its stdout and stderr separately, along with its exit code.
+// The result is returned even if the command fails, so callers can inspect stderr and the exit code.
+func (c *Command) Result() (*Result, error) {
+	if c.isDryRun() {
+		c.logDryRun()
+		return &Result{}, nil
+	}
+	stdout, stderr, combined := &bytes.Buffer{}, &bytes.Buffer{}, &threadsafe.Buffer{}
+	cmd, flush, err := c.build(io.MultiWriter(stdout, combined), io.MultiWriter(stderr, combined))
+	if err != nil {
+		return &Result{ExitCode: -1}, err
+	}
+	err = c.runCmd(cmd)
+	flush()
+	result := &Result{
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		Combined: combined.String(),
+		ExitCode: exitCode(cmd, err),
+	}
+	if err != nil {
+		cmdErr := newCommandError(cmd, err, result.Combined)
+		cmdErr.Stdout, cmdErr.Stderr = truncate(result.Stdout), truncate(result.Stderr)
+		return result, cmdErr
+	}
+	return result, nil
+}
+
+// build returns the exec.Cmd to run, and a func to call once it has exited to flush any streamed output
+func (c *Command) build(stdout, stderr io.Writer) (*exec.Cmd, func(), error) {
+	cmd, err := newExecCmd(c.args)
+	if err != nil {
+		return nil, nil, err
+	}
+	cmd.Dir = c.dir
+	cmd.Env = append(c.baseEnv(), c.env...)
+	cmd.Stdin = c.stdin
+
+	var extra []io.Writer
+	flush := func() {}
+	if c.verbose {
+		fmt.Fprintf(ginkgo.GinkgoWriter, "+ %s\n", c)
+		extra = append(extra, ginkgo.GinkgoWriter)
+	}
+	if c.stream != nil {
+		stream := NewPrefixWriter(c.stream, c.prefix)
+		extra = append(extra, stream)
+		flush = func() { stream.Flush() }
+	}
+	if len(extra) > 0 {
+		if stdout == stderr {
+			// os/exec copies both streams with a single goroutine when they share a writer, which keeps them in order
+			stdout = io.MultiWriter(append([]io.Writer{stdout}, extra...)...)
+			stderr = stdout
+		} else {
+			shared := io.MultiWriter(extra...)
+			stdout = io.MultiWriter(stdout, shared)
+			stderr = io.MultiWriter(stderr, shared)
+		}
+	}
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	return cmd, flush, nil
+}
+
+func newExecCmd(args []string) (*exec.Cmd, error) {
+	if len(args) == 0 {
+		return nil, EmptyCommandError
+	}
+	return exec.Command(args[0], args[1:]...), nil
+}
+
+func (c *Command) baseEnv() []string {
+	if !c.clean {
+		return os.Environ()
+	}
+	// never nil, which would make os/exec use the environment of the current process
+	env := []string{}
+	for _, key := range c.inherit {
+		if value, ok := os.LookupEnv(key); ok {
+			env = append(env, key+"="+value)
+		}
+	}
+	return env
+}
+
+func (c *Command) runCmd(cmd *exec.Cmd) error {
+	ctx := c.ctx
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	return runWithContext(ctx, cmd, c.limits)
+}
@@ -0,0 +1,84 @@
+package exec_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/solo-io/go-utils/testutils/exec"
+)
+
+var _ = Describe("Cmd", func() {
+
+	It("runs in the given dir with the given env and stdin", func() {
+		dir, err := ioutil.TempDir("", "exec-test")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+		Expect(ioutil.WriteFile(filepath.Join(dir, "file"), nil, 0644)).To(Succeed())
+
+		out, err := exec.Cmd("sh", "-c", "ls; echo $GREETING; cat").
+			Dir(dir).
+			Env("GREETING=hello").
+			Stdin(strings.NewReader("input")).
+			Output()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal("file\nhello\ninput"))
+		Expect(os.Getenv("GREETING")).To(BeEmpty())
+	})
+
+	It("kills the command when the timeout passes", func() {
+		start := time.Now()
+		err := exec.Cmd("sh", "-c", "(sleep 30); echo done").Timeout(200 * time.Millisecond).Run()
+		Expect(err).To(MatchError(ContainSubstring("context deadline exceeded")))
+		Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+	})
+
+	It("streams output while returning the result", func() {
+		out := &bytes.Buffer{}
+		result, err := exec.Cmd("sh", "-c", "echo out; echo err >&2").Stream(out, "> ").Result()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Stdout).To(Equal("out\n"))
+		Expect(result.Stderr).To(Equal("err\n"))
+		Expect(out.String()).To(ContainSubstring("> out\n"))
+		Expect(out.String()).To(ContainSubstring("> err\n"))
+	})
+})
+
+var _ = Describe("Cmd environment", func() {
+
+	BeforeEach(func() {
+		os.Setenv("EXEC_TEST_INHERITED", "inherited")
+	})
+
+	AfterEach(func() {
+		os.Unsetenv("EXEC_TEST_INHERITED")
+	})
+
+	It("overrides variables of the current process", func() {
+		out, err := exec.Cmd("sh", "-c", "echo $EXEC_TEST_INHERITED").Env("EXEC_TEST_INHERITED=overridden").Output()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal("overridden\n"))
+		Expect(os.Getenv("EXEC_TEST_INHERITED")).To(Equal("inherited"))
+	})
+
+	It("can start from a clean environment", func() {
+		out, err := exec.Cmd("/bin/sh", "-c", "echo $EXEC_TEST_INHERITED; echo $KUBECONFIG; echo $PATH").
+			CleanEnv("PATH").
+			Env("KUBECONFIG=/tmp/kubeconfig").
+			Output()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal("\n/tmp/kubeconfig\n" + os.Getenv("PATH") + "\n"))
+	})
+	It("fails without a command", func() {
+		_, err := exec.Cmd().Output()
+		Expect(err).To(Equal(exec.EmptyCommandError))
+		result, err := exec.Cmd().Result()
+		Expect(err).To(Equal(exec.EmptyCommandError))
+		Expect(result.ExitCode).To(Equal(-1))
+	})
+})
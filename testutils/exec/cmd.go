@@ -1,15 +1,25 @@
 package exec
 
 import (
-	"bytes"
-	"io"
-	"os"
+	"context"
 	"os/exec"
-
-	"github.com/onsi/ginkgo"
-	"github.com/pkg/errors"
+	"strings"
 )
 
+// Result holds the output of a finished command
+type Result struct {
+	Stdout string
+	Stderr string
+	// stdout and stderr interleaved. Since the streams are read concurrently, the order of
+	// writes that happen close together is not guaranteed; use RunCommandOutput if it matters
+	Combined string
+	// -1 if the command could not be started or was killed by a signal
+	ExitCode int
+}
+
+// The RunCommand functions predate Cmd and are kept for compatibility, new code should use Cmd,
+// which can also set env vars, stdin and a timeout for a single command.
+
 func RunCommand(workingDir string, verbose bool, args ...string) error {
 	_, err := RunCommandOutput(workingDir, verbose, args...)
 	return err
@@ -25,24 +35,92 @@ func RunCommandInput(input, workingDir string, verbose bool, args ...string) err
 }
 
 func RunCommandInputOutput(input, workingDir string, verbose bool, args ...string) (string, error) {
-	cmd := exec.Command(args[0], args[1:]...)
-	cmd.Dir = workingDir
-	cmd.Env = os.Environ()
+	return RunCommandInputOutputContext(context.Background(), input, workingDir, verbose, args...)
+}
+
+// RunCommandContext is like RunCommand, but kills the command and every process it started
+// when ctx is cancelled or its deadline passes.
+func RunCommandContext(ctx context.Context, workingDir string, verbose bool, args ...string) error {
+	_, err := RunCommandOutputContext(ctx, workingDir, verbose, args...)
+	return err
+}
+
+func RunCommandOutputContext(ctx context.Context, workingDir string, verbose bool, args ...string) (string, error) {
+	return RunCommandInputOutputContext(ctx, "", workingDir, verbose, args...)
+}
+
+func RunCommandInputOutputContext(ctx context.Context, input, workingDir string, verbose bool, args ...string) (string, error) {
+	return legacyCmd(ctx, input, workingDir, verbose, args...).Output()
+}
+
+// RunCommandResult runs the command and returns its stdout and stderr separately, along with its exit code.
+// The result is returned even if the command fails, so callers can inspect stderr and the exit code.
+func RunCommandResult(ctx context.Context, input, workingDir string, verbose bool, args ...string) (*Result, error) {
+	return legacyCmd(ctx, input, workingDir, verbose, args...).Result()
+}
+
+// legacyCmd translates the positional arguments of the RunCommand functions into a Command
+func legacyCmd(ctx context.Context, input, workingDir string, verbose bool, args ...string) *Command {
+	cmd := Cmd(args...).Context(ctx).Dir(workingDir).Verbose(verbose)
 	if len(input) > 0 {
-		cmd.Stdin = bytes.NewBuffer([]byte(input))
+		cmd.Stdin(strings.NewReader(input))
 	}
-	buf := &bytes.Buffer{}
-	var out io.Writer
-	if verbose {
-		out = io.MultiWriter(buf, ginkgo.GinkgoWriter)
-	} else {
-		out = buf
+	return cmd
+}
+
+func exitCode(cmd *exec.Cmd, err error) int {
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return exitErr.ExitCode()
 	}
-	cmd.Stdout = out
-	cmd.Stderr = out
-	if err := cmd.Run(); err != nil {
-		return "", errors.Wrapf(err, "%v failed: %s", cmd.Args, buf.String())
+	if cmd.ProcessState == nil {
+		return -1
 	}
+	return cmd.ProcessState.ExitCode()
+}
 
-	return buf.String(), nil
+// runWithContext runs cmd in its own process group, killing the whole group if ctx is done first.
+// If limits is set, they are applied to the process before it runs the command, see Limits.
+func runWithContext(ctx context.Context, cmd *exec.Cmd, limits *Limits) error {
+	if ctx.Done() == nil {
+		// the context can never be cancelled, so leave the command in our process group
+		// where it still receives signals (e.g. ctrl-c) sent to the test process
+		if limits == nil {
+			return cmd.Run()
+		}
+		cleanup, err := limits.start(cmd)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		return cmd.Wait()
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	setProcessGroup(cmd)
+	if limits == nil {
+		if err := cmd.Start(); err != nil {
+			return err
+		}
+	} else {
+		cleanup, err := limits.start(cmd)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+	}
+	exited := make(chan struct{})
+	defer close(exited)
+	go func() {
+		select {
+		case <-ctx.Done():
+			killProcessGroup(cmd)
+		case <-exited:
+		}
+	}()
+	err := cmd.Wait()
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return err
 }
@@ -0,0 +1,65 @@
+package exec_test
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/solo-io/go-utils/testutils/exec"
+)
+
+var _ = Describe("RunCommand", func() {
+
+	It("returns combined output", func() {
+		out, err := exec.RunCommandOutput("", false, "sh", "-c", "echo out; echo err >&2")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal("out\nerr\n"))
+	})
+
+	It("returns stdout, stderr and the exit code separately", func() {
+		result, err := exec.RunCommandResult(context.Background(), "", "", false, "sh", "-c", "echo '{}'; echo warning >&2; exit 3")
+		Expect(err).To(HaveOccurred())
+		Expect(result.Stdout).To(Equal("{}\n"))
+		Expect(result.Stderr).To(Equal("warning\n"))
+		Expect(result.ExitCode).To(Equal(3))
+
+		result, err = exec.RunCommandResult(context.Background(), "input", "", false, "cat")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Stdout).To(Equal("input"))
+		Expect(result.ExitCode).To(Equal(0))
+	})
+
+	It("kills the command and its children when the context times out", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		// the subshell keeps the output pipe open, so this only returns early if the whole group is killed
+		err := exec.RunCommandContext(ctx, "", false, "sh", "-c", "(sleep 30); echo done")
+		Expect(err).To(MatchError(ContainSubstring("context deadline exceeded")))
+		Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+	})
+})
+
+var _ = Describe("RunCommandStreaming", func() {
+
+	It("streams every line with the prefix", func() {
+		out := &bytes.Buffer{}
+		combined, err := exec.RunCommandStreaming(context.Background(), out, "[test] ", "", "sh", "-c", "echo one; echo two; printf three")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(combined).To(Equal("one\ntwo\nthree"))
+		Expect(out.String()).To(Equal("[test] one\n[test] two\n[test] three\n"))
+	})
+
+	It("only writes complete lines", func() {
+		out := &bytes.Buffer{}
+		w := exec.NewPrefixWriter(out, "> ")
+		w.Write([]byte("hel"))
+		Expect(out.String()).To(BeEmpty())
+		w.Write([]byte("lo\nwor"))
+		Expect(out.String()).To(Equal("> hello\n"))
+		Expect(w.Flush()).To(Succeed())
+		Expect(out.String()).To(Equal("> hello\n> wor\n"))
+	})
+})
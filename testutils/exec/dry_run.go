@@ -0,0 +1,106 @@
+package exec
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/onsi/ginkgo"
+)
+
+// DryRunEnvVar enables dry-run mode for every command when set to true, e.g. to see what a CI job would run
+const DryRunEnvVar = "EXEC_DRY_RUN"
+
+var (
+	dryRunLock sync.RWMutex
+	dryRun     = envDryRun()
+	dryRunOut  io.Writer
+)
+
+func envDryRun() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv(DryRunEnvVar))
+	return enabled
+}
+
+// SetDryRun enables or disables dry-run mode for every command. In dry-run mode commands are logged
+// instead of run, and succeed with no output.
+func SetDryRun(enabled bool) {
+	dryRunLock.Lock()
+	defer dryRunLock.Unlock()
+	dryRun = enabled
+}
+
+// SetDryRunOutput sets where commands are logged in dry-run mode, by default the GinkgoWriter
+func SetDryRunOutput(out io.Writer) {
+	dryRunLock.Lock()
+	defer dryRunLock.Unlock()
+	dryRunOut = out
+}
+
+// DryRun enables or disables dry-run mode for this command only, whatever SetDryRun or EXEC_DRY_RUN say, e.g. to
+// still run a read-only command that later steps depend on when dry-run mode is on
+func (c *Command) DryRun(enabled bool) *Command {
+	c.dryRun = &enabled
+	return c
+}
+
+// String renders the command as it would be typed into a shell, including its working dir and
+// the env vars added with Env. The values of sensitive flags and env vars are masked, see RegisterSensitiveFlags
+// and RegisterSensitiveEnvVars.
+func (c *Command) String() string {
+	var parts []string
+	if c.dir != "" {
+		parts = append(parts, "cd", shellQuote(c.dir), "&&")
+	}
+	if c.clean {
+		parts = append(parts, "env", "-i")
+		for _, key := range c.inherit {
+			parts = append(parts, fmt.Sprintf(`%s="$%s"`, key, key))
+		}
+	}
+	for _, env := range maskEnv(c.env) {
+		parts = append(parts, shellQuote(env))
+	}
+	for _, arg := range maskArgs(c.args) {
+		parts = append(parts, shellQuote(arg))
+	}
+	return strings.Join(parts, " ")
+}
+
+func (c *Command) isDryRun() bool {
+	if c.dryRun != nil {
+		return *c.dryRun
+	}
+	dryRunLock.RLock()
+	defer dryRunLock.RUnlock()
+	return dryRun
+}
+
+func (c *Command) logDryRun() {
+	logDryRun(c.String())
+}
+
+func logDryRun(commandLine string) {
+	dryRunLock.RLock()
+	out := dryRunOut
+	dryRunLock.RUnlock()
+	if out == nil {
+		out = ginkgo.GinkgoWriter
+	}
+	fmt.Fprintf(out, "[dry run] %s\n", commandLine)
+}
+
+func shellQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	if strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./=:,+@%", r))
+	}) < 0 {
+		return s
+	}
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
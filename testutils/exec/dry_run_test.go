@@ -0,0 +1,54 @@
+package exec_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/solo-io/go-utils/testutils/exec"
+)
+
+var _ = Describe("Dry run", func() {
+	var (
+		dir string
+		out *bytes.Buffer
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "exec-dry-run")
+		Expect(err).NotTo(HaveOccurred())
+		out = &bytes.Buffer{}
+		exec.SetDryRunOutput(out)
+	})
+
+	AfterEach(func() {
+		exec.SetDryRun(false)
+		exec.SetDryRunOutput(nil)
+		os.RemoveAll(dir)
+	})
+
+	It("logs the command line instead of running the command", func() {
+		result, err := exec.Cmd("touch", "a file").Dir(dir).Env("KUBECONFIG=/tmp/kube config").DryRun(true).Result()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.ExitCode).To(Equal(0))
+		Expect(out.String()).To(Equal("[dry run] cd " + dir + " && 'KUBECONFIG=/tmp/kube config' touch 'a file'\n"))
+		Expect(filepath.Join(dir, "a file")).NotTo(BeAnExistingFile())
+	})
+
+	It("can be enabled for every command", func() {
+		exec.SetDryRun(true)
+		Expect(exec.RunCommand(dir, false, "touch", "file")).To(Succeed())
+		Expect(out.String()).To(Equal("[dry run] cd " + dir + " && touch file\n"))
+		Expect(filepath.Join(dir, "file")).NotTo(BeAnExistingFile())
+	})
+	It("can be disabled for a command when enabled for every command", func() {
+		exec.SetDryRun(true)
+		Expect(exec.Cmd("touch", "file").Dir(dir).DryRun(false).Run()).To(Succeed())
+		Expect(out.String()).To(BeEmpty())
+		Expect(filepath.Join(dir, "file")).To(BeAnExistingFile())
+	})
+})
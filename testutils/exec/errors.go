@@ -0,0 +1,65 @@
+package exec
+
+import (
+	"fmt"
+	"os/exec"
+
+	"github.com/pkg/errors"
+)
+
+// EmptyCommandError is returned when a command is run without any args
+var EmptyCommandError = errors.New("no command to run: args are empty")
+
+// MaxErrorOutputBytes is how much of a failed command's output is kept in its CommandError.
+// The end of the output is kept, since that's usually where the reason for the failure is printed.
+const MaxErrorOutputBytes = 16 * 1024
+
+// CommandError is returned when a command fails. It wraps the underlying error, e.g. an *exec.ExitError or
+// context.DeadlineExceeded, which can be retrieved with errors.Unwrap, errors.Is or errors.Cause.
+type CommandError struct {
+	Args []string
+	Dir  string
+	// -1 if the command could not be started or was killed by a signal
+	ExitCode int
+	// stdout and stderr combined
+	Output string
+	// only set when the streams were captured separately, e.g. by Result
+	Stdout string
+	Stderr string
+	// the pipeline the command was part of, if any
+	Pipeline string
+	Err      error
+}
+
+func newCommandError(cmd *exec.Cmd, err error, output string) *CommandError {
+	return &CommandError{
+		Args:     cmd.Args,
+		Dir:      cmd.Dir,
+		ExitCode: exitCode(cmd, err),
+		Output:   truncate(output),
+		Err:      err,
+	}
+}
+
+func (e *CommandError) Error() string {
+	if e.Pipeline != "" {
+		return fmt.Sprintf("%v failed in pipeline %s: %s: %v", maskArgs(e.Args), e.Pipeline, e.Output, e.Err)
+	}
+	return fmt.Sprintf("%v failed: %s: %v", maskArgs(e.Args), e.Output, e.Err)
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+// Cause supports github.com/pkg/errors.Cause, which the errors returned by this package used to be wrapped with
+func (e *CommandError) Cause() error {
+	return e.Err
+}
+
+func truncate(output string) string {
+	if len(output) <= MaxErrorOutputBytes {
+		return output
+	}
+	return fmt.Sprintf("...(%d bytes truncated)...", len(output)-MaxErrorOutputBytes) + output[len(output)-MaxErrorOutputBytes:]
+}
@@ -0,0 +1,53 @@
+package exec_test
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/solo-io/go-utils/testutils/exec"
+)
+
+var _ = Describe("CommandError", func() {
+
+	It("describes the failed command", func() {
+		dir := os.TempDir()
+		_, err := exec.Cmd("sh", "-c", "echo out; echo err >&2; exit 4").Dir(dir).Result()
+		cmdErr, ok := err.(*exec.CommandError)
+		Expect(ok).To(BeTrue())
+		Expect(cmdErr.Args).To(Equal([]string{"sh", "-c", "echo out; echo err >&2; exit 4"}))
+		Expect(cmdErr.Dir).To(Equal(dir))
+		Expect(cmdErr.ExitCode).To(Equal(4))
+		Expect(cmdErr.Stdout).To(Equal("out\n"))
+		Expect(cmdErr.Stderr).To(Equal("err\n"))
+		Expect(cmdErr.Error()).To(ContainSubstring("exit status 4"))
+	})
+
+	It("wraps the underlying error", func() {
+		err := exec.Cmd("sleep", "10").Timeout(100 * time.Millisecond).Run()
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+		Expect(errors.Cause(err)).To(Equal(context.DeadlineExceeded))
+		Expect(err.(*exec.CommandError).ExitCode).To(Equal(-1))
+	})
+
+	It("keeps the end of long output", func() {
+		err := exec.Cmd("sh", "-c", "head -c 100000 /dev/zero | tr '\\0' x; echo; echo the real error; exit 1").Run()
+		cmdErr := err.(*exec.CommandError)
+		Expect(len(cmdErr.Output)).To(BeNumerically("<", exec.MaxErrorOutputBytes+100))
+		Expect(cmdErr.Output).To(HavePrefix("...("))
+		Expect(strings.TrimSpace(cmdErr.Output)).To(HaveSuffix("the real error"))
+	})
+
+	It("names the pipeline a command failed in", func() {
+		err := exec.Pipe(exec.Cmd("echo", "hi"), exec.Cmd("sh", "-c", "cat; exit 2")).Run()
+		cmdErr := err.(*exec.CommandError)
+		Expect(cmdErr.Args[0]).To(Equal("sh"))
+		Expect(cmdErr.ExitCode).To(Equal(2))
+		Expect(cmdErr.Pipeline).To(Equal("echo hi | sh -c 'cat; exit 2'"))
+		Expect(cmdErr.Output).To(Equal("hi\n"))
+	})
+})
@@ -0,0 +1,62 @@
+package exec
+
+import (
+	"context"
+	"time"
+
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+	"github.com/pkg/errors"
+)
+
+// EventuallyPollInterval is how long the Eventually functions wait between runs of the command
+var EventuallyPollInterval = time.Second
+
+// EventuallyOutputContains re-runs the command until its output contains substr, see EventuallyOutputMatches
+func EventuallyOutputContains(ctx context.Context, substr string, args ...string) (string, error) {
+	return EventuallyOutputMatches(ctx, gomega.ContainSubstring(substr), Cmd(args...))
+}
+
+// EventuallyOutputMatches re-runs cmd every EventuallyPollInterval until it succeeds with output (stdout and
+// stderr combined) that satisfies matcher, and returns that output. If ctx is done first, the error describes
+// why the last output didn't match and that output is returned, so it can be included in the test failure.
+// ctx also bounds each run of the command; cmd itself is left as it is, so it can be run again afterwards.
+func EventuallyOutputMatches(ctx context.Context, matcher types.GomegaMatcher, cmd *Command) (string, error) {
+	run := *cmd
+	cmd = run.Context(ctx)
+	var (
+		lastOutput  string
+		lastFailure error
+	)
+	for {
+		output, failure := matchOutput(matcher, cmd)
+		if failure == nil {
+			return output, nil
+		}
+		if ctx.Err() == nil || lastFailure == nil {
+			// a run killed by the deadline says nothing about the output, so report the previous run instead
+			lastOutput, lastFailure = output, failure
+		}
+		select {
+		case <-ctx.Done():
+			return lastOutput, errors.Wrapf(lastFailure, "%v did not produce the expected output in time", maskArgs(cmd.args))
+		case <-time.After(EventuallyPollInterval):
+		}
+	}
+}
+
+// matchOutput runs cmd once, returning its output and, if it failed or didn't match, why
+func matchOutput(matcher types.GomegaMatcher, cmd *Command) (string, error) {
+	result, err := cmd.Result()
+	if err != nil {
+		return result.Combined, err
+	}
+	matched, err := matcher.Match(result.Combined)
+	if err != nil {
+		return result.Combined, err
+	}
+	if !matched {
+		return result.Combined, errors.New(matcher.FailureMessage(result.Combined))
+	}
+	return result.Combined, nil
+}
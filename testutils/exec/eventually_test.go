@@ -0,0 +1,62 @@
+package exec_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/solo-io/go-utils/testutils/exec"
+)
+
+var _ = Describe("Eventually", func() {
+	var (
+		dir             string
+		defaultInterval time.Duration
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "exec-eventually")
+		Expect(err).NotTo(HaveOccurred())
+		defaultInterval = exec.EventuallyPollInterval
+		exec.EventuallyPollInterval = 10 * time.Millisecond
+	})
+
+	AfterEach(func() {
+		exec.EventuallyPollInterval = defaultInterval
+		os.RemoveAll(dir)
+	})
+
+	It("re-runs the command until the output matches", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		// prints "pending" twice, then "ready"
+		out, err := exec.EventuallyOutputMatches(ctx, MatchRegexp("^ready"),
+			exec.Cmd("sh", "-c", "echo x >> count; test $(wc -l < count) -ge 3 && echo ready || echo pending").Dir(dir))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal("ready\n"))
+	})
+
+	It("returns the last output when the deadline passes", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		out, err := exec.EventuallyOutputContains(ctx, "Running", "echo", "Pending")
+		Expect(err).To(MatchError(ContainSubstring("did not produce the expected output in time")))
+		Expect(err).To(MatchError(ContainSubstring("to contain substring")))
+		Expect(out).To(Equal("Pending\n"))
+	})
+	It("leaves the command's context as it is", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		cmd := exec.Cmd("echo", "Pending")
+		_, err := exec.EventuallyOutputMatches(ctx, ContainSubstring("Running"), cmd)
+		Expect(err).To(HaveOccurred())
+
+		out, err := cmd.Output()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal("Pending\n"))
+	})
+})
@@ -0,0 +1,131 @@
+package exec
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+)
+
+// FakeRunner is a Runner for unit tests. It records every command it is asked to run, and returns the
+// results scripted with OnCommand instead of running them.
+type FakeRunner struct {
+	lock        sync.Mutex
+	invocations []Invocation
+	responses   []*FakeResponse
+}
+
+var _ Runner = &FakeRunner{}
+
+func NewFakeRunner() *FakeRunner {
+	return &FakeRunner{}
+}
+
+// FakeResponse is the scripted result of the commands matching a FakeRunner.OnCommand call
+type FakeResponse struct {
+	prefix []string
+	result Result
+	err    error
+	times  int
+}
+
+// OnCommand scripts the result of commands whose args start with prefix, e.g. OnCommand("kubectl", "get").
+// By default a matching command succeeds with no output. If several responses match a command, the one
+// scripted last is used.
+func (f *FakeRunner) OnCommand(prefix ...string) *FakeResponse {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	response := &FakeResponse{prefix: prefix}
+	f.responses = append(f.responses, response)
+	return response
+}
+
+// Return makes matching commands print stdout and stderr and exit with exitCode. A non-zero exit code
+// makes the command fail with a *CommandError, like a real command would.
+func (r *FakeResponse) Return(stdout, stderr string, exitCode int) *FakeResponse {
+	r.result = Result{Stdout: stdout, Stderr: stderr, Combined: stdout + stderr, ExitCode: exitCode}
+	return r
+}
+
+// Fail makes matching commands fail with err, e.g. to simulate a missing binary
+func (r *FakeResponse) Fail(err error) *FakeResponse {
+	r.err = err
+	r.result.ExitCode = -1
+	return r
+}
+
+// Times limits the response to the next n matching commands, e.g. to script a command that fails once
+// and then succeeds. By default a response is used for every matching command.
+func (r *FakeResponse) Times(n int) *FakeResponse {
+	r.times = n
+	return r
+}
+
+func (r *FakeResponse) matches(args []string) bool {
+	if len(args) < len(r.prefix) {
+		return false
+	}
+	for i, arg := range r.prefix {
+		if args[i] != arg {
+			return false
+		}
+	}
+	return true
+}
+
+// Run records the command and returns the scripted result. Commands with no scripted result fail.
+func (f *FakeRunner) Run(cmd *Command) (*Result, error) {
+	invocation := cmd.Invocation()
+	if cmd.stdin != nil {
+		stdin, err := ioutil.ReadAll(cmd.stdin)
+		if err != nil {
+			return &Result{ExitCode: -1}, err
+		}
+		invocation.Stdin = string(stdin)
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.invocations = append(f.invocations, invocation)
+	for i := len(f.responses) - 1; i >= 0; i-- {
+		response := f.responses[i]
+		if !response.matches(invocation.Args) {
+			continue
+		}
+		if response.times > 0 {
+			response.times--
+			if response.times == 0 {
+				f.responses = append(f.responses[:i], f.responses[i+1:]...)
+			}
+		}
+		return response.run(invocation)
+	}
+	return &Result{ExitCode: -1}, fmt.Errorf("no result scripted for command %s", strings.Join(invocation.Args, " "))
+}
+
+func (r *FakeResponse) run(invocation Invocation) (*Result, error) {
+	result := r.result
+	err := r.err
+	if err == nil && result.ExitCode != 0 {
+		err = fmt.Errorf("exit status %d", result.ExitCode)
+	}
+	if err != nil {
+		return &result, &CommandError{
+			Args:     invocation.Args,
+			Dir:      invocation.Dir,
+			ExitCode: result.ExitCode,
+			Output:   result.Combined,
+			Stdout:   result.Stdout,
+			Stderr:   result.Stderr,
+			Err:      err,
+		}
+	}
+	return &result, nil
+}
+
+// Invocations returns every command run so far, in order
+func (f *FakeRunner) Invocations() []Invocation {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return append([]Invocation(nil), f.invocations...)
+}
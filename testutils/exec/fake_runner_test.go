@@ -0,0 +1,69 @@
+package exec_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/solo-io/go-utils/testutils/exec"
+)
+
+// a consumer of the exec package, as it would be written outside of it
+type podLister struct {
+	runner exec.Runner
+}
+
+func (l *podLister) pods(namespace string) ([]string, error) {
+	result, err := l.runner.Run(exec.Cmd("kubectl", "get", "pods", "-n", namespace, "-o", "name"))
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(result.Stdout), nil
+}
+
+var _ = Describe("FakeRunner", func() {
+	var runner *exec.FakeRunner
+
+	BeforeEach(func() {
+		runner = exec.NewFakeRunner()
+	})
+
+	It("returns scripted results and records invocations", func() {
+		runner.OnCommand("kubectl", "get", "pods").Return("pod/gloo\npod/gateway\n", "", 0)
+		pods, err := (&podLister{runner: runner}).pods("gloo-system")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pods).To(Equal([]string{"pod/gloo", "pod/gateway"}))
+
+		_, err = runner.Run(exec.Cmd("kubectl", "apply", "-f", "-").Dir("/tmp").Env("KUBECONFIG=x").Stdin(strings.NewReader("manifest")))
+		Expect(err).To(MatchError(ContainSubstring("no result scripted for command kubectl apply -f -")))
+
+		Expect(runner.Invocations()).To(Equal([]exec.Invocation{
+			{Args: []string{"kubectl", "get", "pods", "-n", "gloo-system", "-o", "name"}},
+			{Args: []string{"kubectl", "apply", "-f", "-"}, Dir: "/tmp", Env: []string{"KUBECONFIG=x"}, Stdin: "manifest"},
+		}))
+	})
+
+	It("fails like a real command", func() {
+		runner.OnCommand("kubectl").Return("", "connection refused", 1).Times(1)
+		runner.OnCommand("kubectl", "version").Fail(errors.New("executable file not found"))
+
+		result, err := runner.Run(exec.Cmd("kubectl", "get", "pods"))
+		Expect(err).To(HaveOccurred())
+		Expect(err.(*exec.CommandError).Stderr).To(Equal("connection refused"))
+		Expect(result.ExitCode).To(Equal(1))
+
+		// the response was only scripted once
+		_, err = runner.Run(exec.Cmd("kubectl", "get", "pods"))
+		Expect(err).To(MatchError(ContainSubstring("no result scripted")))
+
+		_, err = runner.Run(exec.Cmd("kubectl", "version"))
+		Expect(err).To(MatchError(ContainSubstring("executable file not found")))
+	})
+
+	It("runs real commands with NewRunner", func() {
+		result, err := exec.NewRunner().Run(exec.Cmd("echo", "hi"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Stdout).To(Equal("hi\n"))
+	})
+})
@@ -0,0 +1,56 @@
+package exec
+
+import (
+	"fmt"
+	"os/exec"
+
+	"github.com/onsi/ginkgo"
+)
+
+// Limits bound the resources a command may use, so that a runaway helm or kubectl invocation can't starve the
+// test process. They are only enforced on Linux, and are applied before the command runs anything: it is started
+// held by a shell that waits for the limits to be applied to it before exec'ing the command, so the command and
+// anything it spawns inherit them. Limits that can't be applied (on other platforms, or without the permissions to)
+// are skipped with a warning on the GinkgoWriter rather than failing the command. Use Timeout to bound how
+// long the command may run.
+type Limits struct {
+	// added to the command's niceness, e.g. 10 to give the test process priority. Negative values need privileges.
+	Nice int
+	// without CgroupParent, enforced with RLIMIT_AS, which counts virtual memory, so leave plenty of headroom;
+	// with CgroupParent, enforced with memory.max
+	MaxMemoryBytes uint64
+	// CPU time after which the command is killed, enforced with RLIMIT_CPU in whole seconds
+	MaxCPUSeconds uint64
+	// a cgroup v2 directory the test process can write to, e.g. /sys/fs/cgroup/ci. If set, the command is moved
+	// into a cgroup created under it for as long as it runs.
+	CgroupParent string
+	// with CgroupParent, the number of CPUs the command may use, e.g. 0.5, enforced with cpu.max
+	CPUs float64
+}
+
+// Limits applies limits to the command, see Limits for how they are enforced
+func (c *Command) Limits(limits Limits) *Command {
+	c.limits = &limits
+	return c
+}
+
+// start starts cmd with the limits applied, returning a func to call once it has exited
+func (l *Limits) start(cmd *exec.Cmd) (func(), error) {
+	args := cmd.Args
+	release, err := holdUntilLimited(cmd)
+	if err != nil {
+		// the limits still apply to the command, just not to anything it spawns before they are applied
+		fmt.Fprintf(ginkgo.GinkgoWriter, "[exec] limiting %v only once it has started: %v\n", maskArgs(args), err)
+		release = func() {}
+	}
+	if err := cmd.Start(); err != nil {
+		release()
+		return nil, err
+	}
+	cleanup, errs := applyLimits(cmd.Process.Pid, *l)
+	release()
+	for _, err := range errs {
+		fmt.Fprintf(ginkgo.GinkgoWriter, "[exec] not limiting %v: %v\n", maskArgs(args), err)
+	}
+	return cleanup, nil
+}
@@ -0,0 +1,114 @@
+package exec
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"unsafe"
+
+	"github.com/pkg/errors"
+)
+
+// the period cpu.max quotas are expressed in, in microseconds
+const cgroupCPUPeriod = 100000
+
+// holdUntilLimited makes cmd start as a shell that waits for the returned func to be called, which closes the
+// pipe it reads from, before exec'ing the command. The shell is the process the limits are applied to, and the
+// command keeps them, as well as its pid, when it replaces the shell.
+func holdUntilLimited(cmd *exec.Cmd) (func(), error) {
+	if !filepath.IsAbs(cmd.Path) && filepath.Base(cmd.Path) == cmd.Path {
+		// the command wasn't found in the PATH, let Start fail with the error for that
+		return func() {}, nil
+	}
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		return nil, err
+	}
+	hold, release, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	fd := 3 + len(cmd.ExtraFiles)
+	script := fmt.Sprintf(`read _ <&%d; exec "$@" %d<&-`, fd, fd)
+	cmd.Args = append([]string{"sh", "-c", script, "sh", cmd.Path}, cmd.Args[1:]...)
+	cmd.Path = sh
+	cmd.ExtraFiles = append(cmd.ExtraFiles, hold)
+	return func() {
+		hold.Close()
+		release.Close()
+	}, nil
+}
+
+func applyLimits(pid int, limits Limits) (func(), []error) {
+	var errs []error
+	if limits.Nice != 0 {
+		// getpriority returns 20 - nice, see getpriority(2)
+		current, err := syscall.Getpriority(syscall.PRIO_PROCESS, pid)
+		if err == nil {
+			err = syscall.Setpriority(syscall.PRIO_PROCESS, pid, 20-current+limits.Nice)
+		}
+		if err != nil {
+			errs = append(errs, errors.Wrap(err, "setting niceness"))
+		}
+	}
+	if limits.MaxCPUSeconds > 0 {
+		if err := prlimit(pid, syscall.RLIMIT_CPU, limits.MaxCPUSeconds); err != nil {
+			errs = append(errs, errors.Wrap(err, "limiting cpu time"))
+		}
+	}
+	cleanup := func() {}
+	if limits.CgroupParent != "" {
+		dir, err := joinCgroup(pid, limits)
+		if err != nil {
+			errs = append(errs, errors.Wrap(err, "joining cgroup"))
+		} else {
+			// the cgroup can only be removed once the process has exited; the limits of its parent apply from then on
+			cleanup = func() { os.Remove(dir) }
+		}
+	} else if limits.MaxMemoryBytes > 0 {
+		if err := prlimit(pid, syscall.RLIMIT_AS, limits.MaxMemoryBytes); err != nil {
+			errs = append(errs, errors.Wrap(err, "limiting memory"))
+		}
+	}
+	return cleanup, errs
+}
+
+// prlimit sets the soft and hard limit of resource for the process with the given pid, see prlimit(2)
+func prlimit(pid, resource int, limit uint64) error {
+	rlimit := syscall.Rlimit{Cur: limit, Max: limit}
+	_, _, errno := syscall.RawSyscall6(syscall.SYS_PRLIMIT64, uintptr(pid), uintptr(resource),
+		uintptr(unsafe.Pointer(&rlimit)), 0, 0, 0)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+func joinCgroup(pid int, limits Limits) (string, error) {
+	dir := filepath.Join(limits.CgroupParent, fmt.Sprintf("exec-%d", pid))
+	if err := os.Mkdir(dir, 0755); err != nil {
+		return "", err
+	}
+	files := map[string]string{}
+	if limits.MaxMemoryBytes > 0 {
+		files["memory.max"] = strconv.FormatUint(limits.MaxMemoryBytes, 10)
+	}
+	if limits.CPUs > 0 {
+		files["cpu.max"] = fmt.Sprintf("%d %d", int(limits.CPUs*cgroupCPUPeriod), cgroupCPUPeriod)
+	}
+	for name, value := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(value), 0644); err != nil {
+			os.Remove(dir)
+			return "", err
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "cgroup.procs"), []byte(strconv.Itoa(pid)), 0644); err != nil {
+		os.Remove(dir)
+		return "", err
+	}
+	return dir, nil
+}
@@ -0,0 +1,20 @@
+//go:build !linux
+// +build !linux
+
+package exec
+
+import (
+	"os/exec"
+	"runtime"
+
+	"github.com/pkg/errors"
+)
+
+// the limits can't be applied anyway, so there is no need to hold the command until they are
+func holdUntilLimited(cmd *exec.Cmd) (func(), error) {
+	return func() {}, nil
+}
+
+func applyLimits(pid int, limits Limits) (func(), []error) {
+	return func() {}, []error{errors.Errorf("resource limits are not supported on %s", runtime.GOOS)}
+}
@@ -0,0 +1,70 @@
+package exec_test
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/solo-io/go-utils/testutils/exec"
+)
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+var _ = Describe("Limits", func() {
+
+	BeforeEach(func() {
+		if runtime.GOOS != "linux" {
+			Skip("resource limits are only enforced on linux")
+		}
+	})
+
+	It("applies niceness and cpu and memory limits to the command", func() {
+		out, err := exec.Cmd("sh", "-c", "nice; ulimit -t; ulimit -v").
+			Limits(exec.Limits{Nice: 5, MaxCPUSeconds: 30, MaxMemoryBytes: 4 << 30}).
+			Output()
+		Expect(err).NotTo(HaveOccurred())
+		lines := strings.Fields(out)
+		Expect(lines).To(HaveLen(3))
+		baseline, err := exec.Cmd("nice").Output()
+		Expect(err).NotTo(HaveOccurred())
+		before, err := strconv.Atoi(strings.TrimSpace(baseline))
+		Expect(err).NotTo(HaveOccurred())
+		after, err := strconv.Atoi(lines[0])
+		Expect(err).NotTo(HaveOccurred())
+		// niceness is capped at 19
+		Expect(after).To(Equal(min(before+5, 19)))
+		Expect(lines[1:]).To(Equal([]string{"30", "4194304"}))
+	})
+
+	It("applies the limits before the command spawns anything", func() {
+		out, err := exec.Cmd("sh", "-c", "(ulimit -t) & wait").
+			Limits(exec.Limits{MaxCPUSeconds: 30}).
+			Output()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal("30\n"))
+	})
+
+	It("runs the command with its own arguments and exit code", func() {
+		result, err := exec.Cmd("sh", "-c", `echo "$@"; exit 3`, "sh", "a b", "c").
+			Limits(exec.Limits{MaxCPUSeconds: 30}).
+			Result()
+		Expect(err).To(HaveOccurred())
+		Expect(result.ExitCode).To(Equal(3))
+		Expect(result.Stdout).To(Equal("a b c\n"))
+	})
+
+	It("runs the command without the limits it can't apply", func() {
+		out, err := exec.Cmd("echo", "ran").
+			Limits(exec.Limits{CgroupParent: "/does/not/exist", MaxMemoryBytes: 1 << 30}).
+			Output()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal("ran\n"))
+	})
+})
@@ -0,0 +1,83 @@
+package exec
+
+import (
+	"strings"
+	"sync"
+)
+
+const maskedValue = "****"
+
+var (
+	sensitiveFlagsLock sync.RWMutex
+	sensitiveFlags     = map[string]bool{
+		"--license-key":   true,
+		"--token":         true,
+		"--password":      true,
+		"--client-secret": true,
+	}
+	sensitiveEnvVars = map[string]bool{
+		"GITHUB_TOKEN":          true,
+		"LICENSE_KEY":           true,
+		"AWS_SECRET_ACCESS_KEY": true,
+		"VAULT_TOKEN":           true,
+	}
+)
+
+// RegisterSensitiveFlags adds flags, e.g. "--license-key", whose values are masked wherever this package logs
+// a command line: verbose output, dry runs and the messages of CommandErrors. Both "--flag value" and
+// "--flag=value" are masked. The commands themselves still receive the real values.
+func RegisterSensitiveFlags(flags ...string) {
+	sensitiveFlagsLock.Lock()
+	defer sensitiveFlagsLock.Unlock()
+	for _, flag := range flags {
+		sensitiveFlags[flag] = true
+	}
+}
+
+// RegisterSensitiveEnvVars adds env vars, e.g. "GLOO_LICENSE_KEY", whose values are masked wherever this package
+// logs the variables added to a command with Env, like RegisterSensitiveFlags does for flags
+func RegisterSensitiveEnvVars(keys ...string) {
+	sensitiveFlagsLock.Lock()
+	defer sensitiveFlagsLock.Unlock()
+	for _, key := range keys {
+		sensitiveEnvVars[key] = true
+	}
+}
+
+// maskEnv returns a copy of env, in the form KEY=VALUE, with the values of sensitive variables masked
+func maskEnv(env []string) []string {
+	sensitiveFlagsLock.RLock()
+	defer sensitiveFlagsLock.RUnlock()
+	masked := make([]string, len(env))
+	for i, variable := range env {
+		masked[i] = variable
+		if idx := strings.Index(variable, "="); idx > 0 && sensitiveEnvVars[variable[:idx]] {
+			masked[i] = variable[:idx+1] + maskedValue
+		}
+	}
+	return masked
+}
+
+// maskArgs returns a copy of args with the values of sensitive flags masked
+func maskArgs(args []string) []string {
+	sensitiveFlagsLock.RLock()
+	defer sensitiveFlagsLock.RUnlock()
+	masked := make([]string, len(args))
+	maskNext := false
+	for i, arg := range args {
+		switch {
+		case maskNext:
+			masked[i] = maskedValue
+			maskNext = false
+		case sensitiveFlags[arg]:
+			masked[i] = arg
+			maskNext = true
+		default:
+			masked[i] = arg
+			if idx := strings.Index(arg, "="); idx > 0 && sensitiveFlags[arg[:idx]] {
+				masked[i] = arg[:idx+1] + maskedValue
+			}
+		}
+	}
+	return masked
+}
@@ -0,0 +1,60 @@
+package exec_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/solo-io/go-utils/testutils/exec"
+)
+
+var _ = Describe("Masking sensitive flags", func() {
+
+	BeforeEach(func() {
+		exec.RegisterSensitiveFlags("--api-key")
+	})
+
+	It("masks the values of sensitive flags in command lines", func() {
+		cmd := exec.Cmd("glooctl", "install", "--license-key", "abc123", "--api-key=xyz", "--name", "gloo")
+		Expect(cmd.String()).To(Equal("glooctl install --license-key '****' '--api-key=****' --name gloo"))
+	})
+
+	It("still passes the real values to the command", func() {
+		out, err := exec.Cmd("echo", "--token", "secret").Output()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal("--token secret\n"))
+	})
+
+	It("masks the values of sensitive flags in errors", func() {
+		err := exec.Cmd("sh", "-c", "exit 1", "--password=hunter2").Run()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).NotTo(ContainSubstring("hunter2"))
+		Expect(err.Error()).To(ContainSubstring("--password=****"))
+		cmdErr, ok := err.(*exec.CommandError)
+		Expect(ok).To(BeTrue())
+		Expect(cmdErr.Args).To(ContainElement("--password=hunter2"))
+	})
+	It("masks the values of sensitive env vars in command lines", func() {
+		exec.RegisterSensitiveEnvVars("GLOO_LICENSE_KEY")
+		cmd := exec.Cmd("glooctl", "install").Env("GLOO_LICENSE_KEY=abc123", "GITHUB_TOKEN=xyz", "NAMESPACE=gloo")
+		Expect(cmd.String()).To(Equal("'GLOO_LICENSE_KEY=****' 'GITHUB_TOKEN=****' NAMESPACE=gloo glooctl install"))
+	})
+
+	It("masks the values of sensitive flags in the errors of long-running processes", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, err := exec.StartAndWaitForLine(ctx, exec.LineContains("ready"), "sh", "-c", "exit 1", "--token", "hunter2")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).NotTo(ContainSubstring("hunter2"))
+	})
+
+	It("masks the values of sensitive flags when output never matches", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		_, err := exec.EventuallyOutputContains(ctx, "ready", "echo", "--token", "hunter2")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("did not produce the expected output in time"))
+		Expect(err.Error()).NotTo(ContainSubstring("[echo --token hunter2]"))
+	})
+})
@@ -0,0 +1,143 @@
+package exec
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/solo-io/go-utils/threadsafe"
+)
+
+// Pipeline runs commands with the stdout of each connected to the stdin of the next, like a shell pipeline,
+// without building a shell command line and quoting its arguments:
+//
+//	exec.Pipe(exec.Cmd("helm", "template", chart), exec.Cmd("kubectl", "apply", "-f", "-")).Run()
+type Pipeline struct {
+	cmds []*Command
+}
+
+// Pipe returns a pipeline of cmds. Every command keeps its own dir, env, timeout and context;
+// the stdin of all but the first command is replaced by the output of the previous one.
+func Pipe(cmds ...*Command) *Pipeline {
+	return &Pipeline{cmds: cmds}
+}
+
+// String renders the pipeline as it would be typed into a shell
+func (p *Pipeline) String() string {
+	parts := make([]string, 0, len(p.cmds))
+	for _, c := range p.cmds {
+		parts = append(parts, c.String())
+	}
+	return strings.Join(parts, " | ")
+}
+
+// Run runs the pipeline. If any command fails, the error is a *CommandError including the pipeline's output
+func (p *Pipeline) Run() error {
+	_, err := p.Output()
+	return err
+}
+
+// Output runs the pipeline and returns the stdout of the last command, combined with the stderr of every
+// command. As with `set -o pipefail`, the pipeline fails if any of its commands fails.
+// Whether a command runs in dry-run mode is decided for each command, as for a single command: one in dry-run mode
+// is logged and succeeds, discarding its input without printing anything, while the others still run. If they all
+// are in dry-run mode, the whole pipeline is logged instead.
+func (p *Pipeline) Output() (string, error) {
+	if len(p.cmds) == 0 {
+		return "", nil
+	}
+	dryRuns := 0
+	for _, c := range p.cmds {
+		if c.isDryRun() {
+			dryRuns++
+		}
+	}
+	if dryRuns == len(p.cmds) {
+		logDryRun(p.String())
+		return "", nil
+	}
+
+	buf := &threadsafe.Buffer{}
+	// hide the buffer's ReadFrom, which holds its lock until EOF and would block every other command's output
+	out := io.MultiWriter(buf)
+	cmds := make([]*exec.Cmd, len(p.cmds))
+	flushes := make([]func(), len(p.cmds))
+	// closers[i] closes the pipe ends the parent holds for command i once it exits,
+	// so the next command sees EOF and the previous one sees a closed pipe
+	closers := make([][]*os.File, len(p.cmds))
+	// the input of the commands that are only logged, which is discarded so that the previous command isn't
+	// killed writing to a closed pipe
+	discarded := make([]io.Reader, len(p.cmds))
+	for i, c := range p.cmds {
+		if c.isDryRun() {
+			// leave cmds[i] nil, the command is only logged
+			flushes[i] = func() {}
+			continue
+		}
+		var err error
+		if cmds[i], flushes[i], err = c.build(out, out); err != nil {
+			return "", err
+		}
+	}
+	for i := 0; i < len(cmds)-1; i++ {
+		r, w, err := os.Pipe()
+		if err != nil {
+			closeAll(closers)
+			return "", err
+		}
+		if cmds[i] != nil {
+			cmds[i].Stdout = w
+		}
+		if cmds[i+1] != nil {
+			cmds[i+1].Stdin = r
+		} else {
+			discarded[i+1] = r
+		}
+		closers[i] = append(closers[i], w)
+		closers[i+1] = append(closers[i+1], r)
+	}
+
+	errs := make([]error, len(cmds))
+	wg := sync.WaitGroup{}
+	for i := range cmds {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if cmds[i] == nil {
+				p.cmds[i].logDryRun()
+				if discarded[i] != nil {
+					io.Copy(ioutil.Discard, discarded[i])
+				}
+			} else {
+				errs[i] = p.cmds[i].runCmd(cmds[i])
+			}
+			flushes[i]()
+			for _, f := range closers[i] {
+				f.Close()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// like the shell, report the last command that failed: an earlier failure is often
+	// just a broken pipe caused by a later command exiting
+	for i := len(errs) - 1; i >= 0; i-- {
+		if errs[i] != nil {
+			cmdErr := newCommandError(cmds[i], errs[i], buf.String())
+			cmdErr.Pipeline = p.String()
+			return "", cmdErr
+		}
+	}
+	return buf.String(), nil
+}
+
+func closeAll(closers [][]*os.File) {
+	for _, files := range closers {
+		for _, f := range files {
+			f.Close()
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package exec_test
+
+import (
+	"bytes"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/solo-io/go-utils/testutils/exec"
+)
+
+var _ = Describe("Pipe", func() {
+
+	It("pipes the output of each command into the next", func() {
+		out, err := exec.Pipe(
+			exec.Cmd("cat").Stdin(strings.NewReader("b\na\n\"quoted arg\"\n")),
+			exec.Cmd("sort"),
+			exec.Cmd("head", "-n", "2"),
+		).Output()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal("\"quoted arg\"\na\n"))
+	})
+
+	It("fails if any command fails", func() {
+		_, err := exec.Pipe(exec.Cmd("sh", "-c", "echo broken >&2; exit 3"), exec.Cmd("cat")).Output()
+		Expect(err).To(MatchError(ContainSubstring("exit status 3")))
+		Expect(err).To(MatchError(ContainSubstring("broken")))
+	})
+
+	It("renders the pipeline in dry-run mode", func() {
+		out := &bytes.Buffer{}
+		exec.SetDryRunOutput(out)
+		defer exec.SetDryRunOutput(nil)
+		Expect(exec.Pipe(exec.Cmd("helm", "template", "my chart").DryRun(true), exec.Cmd("kubectl", "apply", "-f", "-").DryRun(true)).Run()).To(Succeed())
+		Expect(out.String()).To(Equal("[dry run] helm template 'my chart' | kubectl apply -f -\n"))
+	})
+	It("decides for each command whether it runs in dry-run mode", func() {
+		out := &bytes.Buffer{}
+		exec.SetDryRunOutput(out)
+		exec.SetDryRun(true)
+		defer func() {
+			exec.SetDryRun(false)
+			exec.SetDryRunOutput(nil)
+		}()
+		output, err := exec.Pipe(
+			exec.Cmd("seq", "100000").DryRun(false),
+			exec.Cmd("kubectl", "apply", "-f", "-"),
+			exec.Cmd("sh", "-c", "cat; echo ran").DryRun(false),
+		).Output()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(output).To(Equal("ran\n"))
+		Expect(out.String()).To(Equal("[dry run] kubectl apply -f -\n"))
+	})
+})
@@ -0,0 +1,118 @@
+package exec
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/solo-io/go-utils/threadsafe"
+)
+
+// Process is a long-running command started by StartAndWaitForLine
+type Process struct {
+	cmd    *exec.Cmd
+	output *threadsafe.Buffer
+	done   chan struct{}
+	err    error
+}
+
+// LineContains returns a line matcher matching lines that contain substr
+func LineContains(substr string) func(line string) bool {
+	return func(line string) bool {
+		return strings.Contains(line, substr)
+	}
+}
+
+// StartAndWaitForLine starts a long-running process (a port-forward, a dev server, ...) and blocks until
+// a line of its stdout or stderr satisfies matcher. ctx only bounds the wait: once the line has been seen
+// the process keeps running until Stop is called. If ctx is done or the process exits before a matching
+// line is printed, the process is killed and an error including its output is returned.
+func StartAndWaitForLine(ctx context.Context, matcher func(line string) bool, args ...string) (*Process, error) {
+	cmd, err := newExecCmd(args)
+	if err != nil {
+		return nil, err
+	}
+	cmd.Env = os.Environ()
+	pr, pw := io.Pipe()
+	cmd.Stdout = pw
+	cmd.Stderr = pw
+	setProcessGroup(cmd)
+
+	p := &Process{
+		cmd:    cmd,
+		output: &threadsafe.Buffer{},
+		done:   make(chan struct{}),
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, errors.Wrapf(err, "%v failed to start", maskArgs(cmd.Args))
+	}
+
+	matched := make(chan struct{})
+	scanned := make(chan struct{})
+	var matchOnce sync.Once
+	go func() {
+		defer close(scanned)
+		scanner := bufio.NewScanner(pr)
+		for scanner.Scan() {
+			line := scanner.Text()
+			p.output.Write([]byte(line + "\n"))
+			if matcher(line) {
+				matchOnce.Do(func() { close(matched) })
+			}
+		}
+		// keep draining so the process never blocks on a full pipe after a very long line
+		io.Copy(p.output, pr)
+	}()
+	go func() {
+		p.err = cmd.Wait()
+		pw.Close()
+		// done also means all of the output has been read, so errors built after it include the last lines
+		<-scanned
+		close(p.done)
+	}()
+
+	select {
+	case <-matched:
+		return p, nil
+	case <-p.done:
+		return nil, errors.Errorf("%v exited before printing the expected line: %v: %s", maskArgs(cmd.Args), p.err, p.output.String())
+	case <-ctx.Done():
+		p.Stop()
+		return nil, errors.Wrapf(ctx.Err(), "%v did not print the expected line: %s", maskArgs(cmd.Args), p.output.String())
+	}
+}
+
+// Output returns everything the process has printed so far
+func (p *Process) Output() string {
+	return p.output.String()
+}
+
+// Done is closed when the process exits
+func (p *Process) Done() <-chan struct{} {
+	return p.done
+}
+
+// Stop kills the process, along with any children it started, and waits for it to exit
+func (p *Process) Stop() error {
+	select {
+	case <-p.done:
+		return nil
+	default:
+	}
+	if err := killProcessGroup(p.cmd); err != nil {
+		select {
+		case <-p.done:
+			// it exited on its own in the meantime
+			return nil
+		default:
+			return err
+		}
+	}
+	<-p.done
+	return nil
+}
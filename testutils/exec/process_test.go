@@ -0,0 +1,49 @@
+package exec_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/solo-io/go-utils/testutils/exec"
+)
+
+var _ = Describe("StartAndWaitForLine", func() {
+	var ctx context.Context
+	var cancel context.CancelFunc
+
+	BeforeEach(func() {
+		ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	It("returns once the line is printed and keeps the process running", func() {
+		p, err := exec.StartAndWaitForLine(ctx, exec.LineContains("ready"), "sh", "-c", "echo starting; echo ready >&2; sleep 30")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.Output()).To(Equal("starting\nready\n"))
+		Consistently(p.Done(), 100*time.Millisecond).ShouldNot(BeClosed())
+		Expect(p.Stop()).NotTo(HaveOccurred())
+		Expect(p.Done()).To(BeClosed())
+	})
+
+	It("fails if the process exits first", func() {
+		_, err := exec.StartAndWaitForLine(ctx, exec.LineContains("ready"), "sh", "-c", "echo starting; echo crashed; exit 1")
+		Expect(err).To(MatchError(ContainSubstring("starting\ncrashed\n")))
+	})
+
+	It("fails without a command", func() {
+		_, err := exec.StartAndWaitForLine(ctx, exec.LineContains("ready"))
+		Expect(err).To(Equal(exec.EmptyCommandError))
+	})
+
+	It("fails if the context is done first", func() {
+		ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+		_, err := exec.StartAndWaitForLine(ctx, exec.LineContains("ready"), "sh", "-c", "echo waiting; sleep 30")
+		Expect(err).To(MatchError(ContainSubstring("context deadline exceeded")))
+	})
+})
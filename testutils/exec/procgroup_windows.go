@@ -0,0 +1,19 @@
+package exec
+
+import (
+	"os/exec"
+	"strconv"
+)
+
+// windows has no process groups to set; killProcessGroup kills the process tree instead
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// kill the process along with everything it started, which could otherwise keep running and hold on to the
+// command's output pipes, so that Wait doesn't return
+func killProcessGroup(cmd *exec.Cmd) error {
+	if err := exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(cmd.Process.Pid)).Run(); err != nil {
+		// e.g. taskkill isn't available, so at least kill the process itself
+		return cmd.Process.Kill()
+	}
+	return nil
+}
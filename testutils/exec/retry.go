@@ -0,0 +1,92 @@
+package exec
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	DefaultRetryAttempts = 3
+	DefaultRetryBackoff  = time.Second
+)
+
+// RetryPolicy controls how RunWithRetries retries a failing command
+type RetryPolicy struct {
+	// the maximum number of times the command is run, defaults to DefaultRetryAttempts
+	Attempts int
+	// how long to wait after the first failure, defaults to DefaultRetryBackoff
+	Backoff time.Duration
+	// the wait is multiplied by Factor after every failure, defaults to 2
+	Factor float64
+	// if set, the wait never grows past MaxBackoff
+	MaxBackoff time.Duration
+	// decides whether a failed attempt should be retried, defaults to retrying every failure
+	Retryable func(result *Result, err error) bool
+}
+
+// RetryOnExitCodes returns a Retryable that only retries attempts that exited with one of codes
+func RetryOnExitCodes(codes ...int) func(result *Result, err error) bool {
+	return func(result *Result, err error) bool {
+		for _, code := range codes {
+			if result.ExitCode == code {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// RetryOnOutput returns a Retryable that only retries attempts whose output contains one of substrs,
+// e.g. RetryOnOutput("connection refused", "i/o timeout")
+func RetryOnOutput(substrs ...string) func(result *Result, err error) bool {
+	return func(result *Result, err error) bool {
+		for _, substr := range substrs {
+			if strings.Contains(result.Combined, substr) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// RunWithRetries runs cmd until it succeeds, the policy decides a failure is not retryable, or it has been
+// attempted policy.Attempts times, and returns the result of the last attempt. Waiting between attempts stops
+// early if the command's context is done. The command's stdin, if any, is consumed by the first attempt and
+// not rewound, so commands that read stdin should not be retried.
+func RunWithRetries(policy RetryPolicy, cmd *Command) (*Result, error) {
+	attempts := policy.Attempts
+	if attempts <= 0 {
+		attempts = DefaultRetryAttempts
+	}
+	backoff := policy.Backoff
+	if backoff <= 0 {
+		backoff = DefaultRetryBackoff
+	}
+	factor := policy.Factor
+	if factor <= 0 {
+		factor = 2
+	}
+
+	var (
+		result *Result
+		err    error
+	)
+	for attempt := 1; ; attempt++ {
+		result, err = cmd.Result()
+		if err == nil || attempt >= attempts || cmd.ctx.Err() != nil {
+			return result, err
+		}
+		if policy.Retryable != nil && !policy.Retryable(result, err) {
+			return result, err
+		}
+		select {
+		case <-cmd.ctx.Done():
+			return result, err
+		case <-time.After(backoff):
+		}
+		backoff = time.Duration(float64(backoff) * factor)
+		if policy.MaxBackoff > 0 && backoff > policy.MaxBackoff {
+			backoff = policy.MaxBackoff
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package exec_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/solo-io/go-utils/testutils/exec"
+)
+
+var _ = Describe("RunWithRetries", func() {
+	var (
+		dir string
+		// fails with exit code 2 until it has been run three times
+		flaky []string
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "exec-retry")
+		Expect(err).NotTo(HaveOccurred())
+		flaky = []string{"sh", "-c", "echo x >> count; test $(wc -l < count) -ge 3 || { echo connection refused; exit 2; }"}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	attempts := func() int {
+		b, err := ioutil.ReadFile(filepath.Join(dir, "count"))
+		Expect(err).NotTo(HaveOccurred())
+		return len(b) / 2
+	}
+
+	It("retries until the command succeeds", func() {
+		result, err := exec.RunWithRetries(exec.RetryPolicy{Attempts: 5, Backoff: time.Millisecond}, exec.Cmd(flaky...).Dir(dir))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.ExitCode).To(Equal(0))
+		Expect(attempts()).To(Equal(3))
+	})
+
+	It("gives up after the maximum number of attempts", func() {
+		result, err := exec.RunWithRetries(exec.RetryPolicy{Attempts: 2, Backoff: time.Millisecond}, exec.Cmd(flaky...).Dir(dir))
+		Expect(err).To(HaveOccurred())
+		Expect(result.ExitCode).To(Equal(2))
+		Expect(attempts()).To(Equal(2))
+	})
+
+	It("only retries failures the policy considers retryable", func() {
+		policy := exec.RetryPolicy{Attempts: 5, Backoff: time.Millisecond, Retryable: exec.RetryOnExitCodes(1)}
+		_, err := exec.RunWithRetries(policy, exec.Cmd(flaky...).Dir(dir))
+		Expect(err).To(HaveOccurred())
+		Expect(attempts()).To(Equal(1))
+
+		os.Remove(filepath.Join(dir, "count"))
+		policy.Retryable = exec.RetryOnOutput("connection refused")
+		_, err = exec.RunWithRetries(policy, exec.Cmd(flaky...).Dir(dir))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(attempts()).To(Equal(3))
+	})
+})
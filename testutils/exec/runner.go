@@ -0,0 +1,38 @@
+package exec
+
+// Runner runs commands. Packages that run commands should take a Runner rather than running them directly,
+// so that their tests can use a FakeRunner instead of spawning processes.
+type Runner interface {
+	// Run runs cmd, returning its result even if it fails, like Command.Result
+	Run(cmd *Command) (*Result, error)
+}
+
+// Invocation describes a command that was run
+type Invocation struct {
+	Args []string
+	Dir  string
+	// the variables added with Env
+	Env []string
+	// the command's input, only recorded by FakeRunner since reading it consumes it
+	Stdin string
+}
+
+// Invocation returns the description of the command
+func (c *Command) Invocation() Invocation {
+	return Invocation{
+		Args: append([]string(nil), c.args...),
+		Dir:  c.dir,
+		Env:  append([]string(nil), c.env...),
+	}
+}
+
+type processRunner struct{}
+
+// NewRunner returns a Runner that runs commands as processes
+func NewRunner() Runner {
+	return processRunner{}
+}
+
+func (processRunner) Run(cmd *Command) (*Result, error) {
+	return cmd.Result()
+}
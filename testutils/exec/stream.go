@@ -0,0 +1,65 @@
+package exec
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+)
+
+// PrefixWriter writes every line written to it to an underlying writer, preceded by a prefix.
+// Partial lines are buffered until they are completed or Flush is called.
+// It is safe to write to a PrefixWriter from multiple goroutines, e.g. a command's stdout and stderr.
+type PrefixWriter struct {
+	lock    sync.Mutex
+	out     io.Writer
+	prefix  []byte
+	partial []byte
+}
+
+func NewPrefixWriter(out io.Writer, prefix string) *PrefixWriter {
+	return &PrefixWriter{
+		out:    out,
+		prefix: []byte(prefix),
+	}
+}
+
+func (w *PrefixWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.partial = append(w.partial, p...)
+	for {
+		idx := bytes.IndexByte(w.partial, '\n')
+		if idx < 0 {
+			return len(p), nil
+		}
+		if err := w.writeLine(w.partial[:idx+1]); err != nil {
+			return len(p), err
+		}
+		w.partial = w.partial[idx+1:]
+	}
+}
+
+// Flush writes any buffered partial line, terminated with a newline
+func (w *PrefixWriter) Flush() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if len(w.partial) == 0 {
+		return nil
+	}
+	line := append(w.partial, '\n')
+	w.partial = nil
+	return w.writeLine(line)
+}
+
+func (w *PrefixWriter) writeLine(line []byte) error {
+	_, err := w.out.Write(append(append([]byte{}, w.prefix...), line...))
+	return err
+}
+
+// RunCommandStreaming runs the command like RunCommandOutputContext, but also streams its output to out
+// line by line as it is printed, with every line preceded by prefix. This lets long-running commands
+// (installs, builds, ...) show live progress, e.g. RunCommandStreaming(ctx, GinkgoWriter, "[helm] ", ...).
+func RunCommandStreaming(ctx context.Context, out io.Writer, prefix, workingDir string, args ...string) (string, error) {
+	return Cmd(args...).Context(ctx).Dir(workingDir).Stream(out, prefix).Output()
+}
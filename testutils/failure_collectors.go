@@ -0,0 +1,113 @@
+package testutils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/config"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ArtifactsDirEnvVar overrides where failure artifacts are written
+	ArtifactsDirEnvVar  = "TEST_ARTIFACTS_DIR"
+	DefaultArtifactsDir = "_test_artifacts"
+)
+
+// FailureCollector writes diagnostics about a failed spec (logs, resource dumps, ...) into dir.
+type FailureCollector func(dir string) error
+
+var (
+	collectorsLock sync.Mutex
+	collectors     = map[string]FailureCollector{}
+
+	unsafePathChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+)
+
+// RegisterFailureCollector adds a collector that runs only when a spec fails. Registering a collector
+// with a name that is already in use replaces the previous one. The returned function removes the
+// collector again, which is useful for collectors that only make sense for the lifetime of a resource.
+func RegisterFailureCollector(name string, collector FailureCollector) (deregister func()) {
+	collectorsLock.Lock()
+	defer collectorsLock.Unlock()
+	collectors[name] = collector
+	return func() {
+		collectorsLock.Lock()
+		defer collectorsLock.Unlock()
+		delete(collectors, name)
+	}
+}
+
+// CollectOnFailure runs every registered collector if the current spec has failed.
+// Call it from an AfterEach (or JustAfterEach) in the suite:
+//
+//   var _ = AfterEach(func() {
+//       Expect(testutils.CollectOnFailure()).NotTo(HaveOccurred())
+//   })
+func CollectOnFailure() error {
+	description := CurrentGinkgoTestDescription()
+	if !description.Failed {
+		return nil
+	}
+	return CollectFailureArtifacts(SpecArtifactsDir(description.FullTestText))
+}
+
+// CollectFailureArtifacts runs every registered collector in name order, each in its own
+// subdirectory of dir. Errors from individual collectors are aggregated.
+func CollectFailureArtifacts(dir string) error {
+	collectorsLock.Lock()
+	var names []string
+	toRun := make(map[string]FailureCollector, len(collectors))
+	for name, collector := range collectors {
+		names = append(names, name)
+		toRun[name] = collector
+	}
+	collectorsLock.Unlock()
+	sort.Strings(names)
+
+	var result *multierror.Error
+	for _, name := range names {
+		collectorDir := filepath.Join(dir, sanitizePathElement(name))
+		if err := os.MkdirAll(collectorDir, 0755); err != nil {
+			result = multierror.Append(result, err)
+			continue
+		}
+		if err := toRun[name](collectorDir); err != nil {
+			result = multierror.Append(result, errors.Wrapf(err, "failure collector %s", name))
+		}
+	}
+	return result.ErrorOrNil()
+}
+
+// SpecArtifactsDir returns the directory artifacts for the given spec are written to. Each parallel
+// ginkgo node gets its own directory so that concurrently failing specs never share files.
+func SpecArtifactsDir(specText string) string {
+	base := os.Getenv(ArtifactsDirEnvVar)
+	if base == "" {
+		base = DefaultArtifactsDir
+	}
+	node := fmt.Sprintf("node-%d", config.GinkgoConfig.ParallelNode)
+	return filepath.Join(base, node, sanitizePathElement(specText))
+}
+
+// the longest name sanitizePathElement returns, which keeps paths well below the limits of common file systems
+const maxPathElementLength = 100
+
+func sanitizePathElement(s string) string {
+	sanitized := unsafePathChars.ReplaceAllString(s, "_")
+	if len(sanitized) <= maxPathElementLength {
+		return sanitized
+	}
+	// names sharing a long prefix would end up in the same directory, so keep them apart with a hash of the full name
+	sum := sha256.Sum256([]byte(s))
+	suffix := "-" + hex.EncodeToString(sum[:])[:8]
+	return sanitized[:maxPathElementLength-len(suffix)] + suffix
+}
@@ -0,0 +1,74 @@
+package testutils_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	. "github.com/solo-io/go-utils/testutils"
+)
+
+var _ = Describe("failure collectors", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "artifacts")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("runs collectors in their own directories and aggregates errors", func() {
+		deregisterLogs := RegisterFailureCollector("pod logs", func(dir string) error {
+			return ioutil.WriteFile(filepath.Join(dir, "logs.txt"), []byte("log line"), 0644)
+		})
+		defer deregisterLogs()
+		deregisterBroken := RegisterFailureCollector("broken", func(dir string) error {
+			return errors.New("cannot collect")
+		})
+		defer deregisterBroken()
+
+		err := CollectFailureArtifacts(dir)
+		Expect(err).To(MatchError(ContainSubstring("failure collector broken: cannot collect")))
+		Expect(filepath.Join(dir, "pod_logs", "logs.txt")).To(BeAnExistingFile())
+	})
+
+	It("does not run deregistered collectors", func() {
+		called := false
+		RegisterFailureCollector("once", func(dir string) error {
+			called = true
+			return nil
+		})()
+		Expect(CollectFailureArtifacts(dir)).NotTo(HaveOccurred())
+		Expect(called).To(BeFalse())
+	})
+
+	It("does nothing when the spec passed", func() {
+		called := false
+		defer RegisterFailureCollector("passing", func(dir string) error {
+			called = true
+			return nil
+		})()
+		Expect(CollectOnFailure()).NotTo(HaveOccurred())
+		Expect(called).To(BeFalse())
+	})
+
+	It("uses a separate directory per parallel node", func() {
+		Expect(SpecArtifactsDir("my spec/with slashes")).To(Equal(filepath.Join(DefaultArtifactsDir, "node-1", "my_spec_with_slashes")))
+	})
+	It("keeps specs with long shared prefixes apart", func() {
+		prefix := strings.Repeat("a very long description ", 10)
+		first, second := SpecArtifactsDir(prefix+"first"), SpecArtifactsDir(prefix+"second")
+		Expect(first).NotTo(Equal(second))
+		Expect(len(filepath.Base(first))).To(Equal(100))
+		Expect(filepath.Base(first)).To(HavePrefix("a_very_long_description_"))
+		Expect(SpecArtifactsDir(prefix + "first")).To(Equal(first))
+	})
+})
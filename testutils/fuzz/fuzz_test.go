@@ -0,0 +1,141 @@
+package fuzz_test
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	"github.com/gogo/protobuf/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/solo-io/go-utils/testutils/fuzz"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+var _ = Describe("Generator", func() {
+
+	It("generates the same messages for the same seed", func() {
+		first, second := &types.Struct{}, &types.Struct{}
+		fuzz.NewGenerator(42).Fill(first)
+		fuzz.NewGenerator(42).Fill(second)
+		Expect(first).To(Equal(second))
+	})
+
+	It("sets oneofs to one of their options", func() {
+		generator := fuzz.NewGenerator(1)
+		kinds := map[string]bool{}
+		for i := 0; i < 100; i++ {
+			value := &types.Value{}
+			generator.Fill(value)
+			Expect(value.Kind).NotTo(BeNil())
+			kinds[fmt.Sprintf("%T", value.Kind)] = true
+		}
+		Expect(kinds).To(HaveKey("*types.Value_StringValue"))
+		Expect(kinds).To(HaveKey("*types.Value_StructValue"))
+	})
+
+	It("sets the oneofs of messages generated with the protobuf APIv2", func() {
+		generator := fuzz.NewGenerator(1)
+		kinds := map[string]bool{}
+		for i := 0; i < 100; i++ {
+			value := &structpb.Value{}
+			generator.Fill(value)
+			Expect(value.Kind).NotTo(BeNil())
+			kinds[fmt.Sprintf("%T", value.Kind)] = true
+		}
+		Expect(kinds).To(HaveKey("*structpb.Value_StringValue"))
+		Expect(kinds).To(HaveKey("*structpb.Value_StructValue"))
+	})
+
+	It("only uses named enum values", func() {
+		generator := fuzz.NewGenerator(1)
+		for i := 0; i < 100; i++ {
+			field := &descriptor.FieldDescriptorProto{}
+			generator.Fill(field)
+			Expect(field.Type).NotTo(BeNil())
+			Expect(field.Type.String()).NotTo(Equal(strconv.Itoa(int(*field.Type))))
+		}
+	})
+
+	It("leaves skipped fields unset", func() {
+		generator := fuzz.NewGenerator(1)
+		generator.SkipFields = []string{"Name"}
+		field := &descriptor.FieldDescriptorProto{}
+		generator.Fill(field)
+		Expect(field.Name).To(BeNil())
+	})
+})
+
+var _ = Describe("Run", func() {
+
+	AfterEach(func() {
+		os.Unsetenv(fuzz.SeedEnvVar)
+	})
+
+	It("succeeds when the property holds", func() {
+		Expect(fuzz.Run(&types.Struct{}, fuzz.Config{}, func(input interface{}) error {
+			return nil
+		})).To(Succeed())
+	})
+
+	It("reports the seed and shrinks failing inputs", func() {
+		err := fuzz.Run(&types.Struct{}, fuzz.Config{Seed: 7}, func(input interface{}) error {
+			if len(input.(*types.Struct).Fields) > 0 {
+				return fmt.Errorf("fields aren't supported")
+			}
+			return nil
+		})
+		failure, ok := err.(*fuzz.FailureError)
+		Expect(ok).To(BeTrue())
+		Expect(failure.Seed).To(Equal(int64(7)))
+		Expect(failure.Error()).To(ContainSubstring("rerun with FUZZ_SEED=7"))
+
+		shrunk := failure.Shrunk.(*types.Struct)
+		Expect(shrunk.Fields).To(HaveLen(1))
+		for _, value := range shrunk.Fields {
+			Expect(value.Kind).To(BeNil())
+		}
+		// the original input is reported as it was generated
+		Expect(len(failure.Input.(*types.Struct).Fields)).To(BeNumerically(">=", 1))
+	})
+
+	It("reports and shrinks the generated input when the property modifies it", func() {
+		var failing string
+		err := fuzz.Run(&types.Struct{}, fuzz.Config{Seed: 7}, func(input interface{}) error {
+			msg := input.(*types.Struct)
+			fields := len(msg.Fields)
+			if failing == "" && fields > 0 {
+				failing = msg.String()
+			}
+			msg.Fields = nil
+			if fields > 0 {
+				return fmt.Errorf("fields aren't supported")
+			}
+			return nil
+		})
+		failure, ok := err.(*fuzz.FailureError)
+		Expect(ok).To(BeTrue())
+		Expect(failure.Input.(*types.Struct).String()).To(Equal(failing))
+		Expect(failure.Shrunk.(*types.Struct).Fields).To(HaveLen(1))
+	})
+
+	It("reproduces inputs from the seed in the environment", func() {
+		var inputs []string
+		record := func(input interface{}) error {
+			inputs = append(inputs, input.(*types.Struct).String())
+			return nil
+		}
+		os.Setenv(fuzz.SeedEnvVar, "1234")
+		Expect(fuzz.Run(&types.Struct{}, fuzz.Config{Iterations: 5}, record)).To(Succeed())
+		Expect(fuzz.Run(&types.Struct{}, fuzz.Config{Iterations: 5}, record)).To(Succeed())
+		Expect(inputs[:5]).To(Equal(inputs[5:]))
+	})
+
+	It("treats panics as failures", func() {
+		err := fuzz.Run(&types.Struct{}, fuzz.Config{ShrinkBudget: -1}, func(input interface{}) error {
+			panic("boom")
+		})
+		Expect(err).To(MatchError(ContainSubstring("panic: boom")))
+	})
+})
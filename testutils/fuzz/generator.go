@@ -0,0 +1,236 @@
+package fuzz
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+	DefaultMaxDepth       = 5
+	DefaultMaxCollection  = 3
+	DefaultMaxStringRunes = 12
+)
+
+var stringRunes = []rune("abcdefghijklmnopqrstuvwxyz0123456789-_./")
+
+type enum interface {
+	String() string
+}
+
+// Generator fills Go structs, including generated gogo and golang protobuf messages, with random values.
+// Oneofs are set to one of their options and enums only to values that have a name, so the results are
+// valid messages; anything more specific to the product, such as references between resources, can be
+// fixed up by the caller after Fill.
+type Generator struct {
+	// nested messages deeper than this are left nil, which also ends recursive types like google.protobuf.Value
+	MaxDepth int
+	// the maximum number of elements in generated slices and maps
+	MaxCollection int
+	// the maximum length of generated strings
+	MaxStringRunes int
+	// names of struct fields, e.g. "Metadata", to leave unset
+	SkipFields []string
+
+	rand *rand.Rand
+}
+
+// NewGenerator returns a Generator whose output is determined by seed
+func NewGenerator(seed int64) *Generator {
+	return &Generator{
+		MaxDepth:       DefaultMaxDepth,
+		MaxCollection:  DefaultMaxCollection,
+		MaxStringRunes: DefaultMaxStringRunes,
+		rand:           rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Fill sets the fields of the struct ptr points to to random values
+func (g *Generator) Fill(ptr interface{}) {
+	value := reflect.ValueOf(ptr)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		panic(fmt.Sprintf("fuzz: Fill needs a non-nil pointer, got %T", ptr))
+	}
+	g.fill(value.Elem(), 0)
+}
+
+func (g *Generator) fill(value reflect.Value, depth int) {
+	if enumValues := namedEnumValues(value.Type()); len(enumValues) > 0 {
+		value.SetInt(enumValues[g.rand.Intn(len(enumValues))])
+		return
+	}
+	switch value.Kind() {
+	case reflect.Struct:
+		g.fillStruct(value, depth)
+	case reflect.Ptr:
+		if depth >= g.MaxDepth {
+			return
+		}
+		value.Set(reflect.New(value.Type().Elem()))
+		g.fill(value.Elem(), depth+1)
+	case reflect.Slice:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, g.rand.Intn(g.MaxStringRunes+1))
+			g.rand.Read(b)
+			value.SetBytes(b)
+			return
+		}
+		if depth >= g.MaxDepth {
+			return
+		}
+		n := g.rand.Intn(g.MaxCollection + 1)
+		slice := reflect.MakeSlice(value.Type(), n, n)
+		for i := 0; i < n; i++ {
+			g.fill(slice.Index(i), depth+1)
+		}
+		value.Set(slice)
+	case reflect.Map:
+		if depth >= g.MaxDepth {
+			return
+		}
+		m := reflect.MakeMap(value.Type())
+		for i := g.rand.Intn(g.MaxCollection + 1); i > 0; i-- {
+			key, elem := reflect.New(value.Type().Key()).Elem(), reflect.New(value.Type().Elem()).Elem()
+			g.fill(key, depth+1)
+			g.fill(elem, depth+1)
+			m.SetMapIndex(key, elem)
+		}
+		value.Set(m)
+	case reflect.String:
+		runes := make([]rune, g.rand.Intn(g.MaxStringRunes+1))
+		for i := range runes {
+			runes[i] = stringRunes[g.rand.Intn(len(stringRunes))]
+		}
+		value.SetString(string(runes))
+	case reflect.Bool:
+		value.SetBool(g.rand.Intn(2) == 1)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		// mostly small numbers, which are what configs contain, with the occasional extreme
+		if g.rand.Intn(4) == 0 {
+			value.SetInt(int64(g.rand.Uint64()) >> uint(64-value.Type().Bits()))
+		} else {
+			value.SetInt(int64(g.rand.Intn(201) - 100))
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if g.rand.Intn(4) == 0 {
+			value.SetUint(g.rand.Uint64() >> uint(64-value.Type().Bits()))
+		} else {
+			value.SetUint(uint64(g.rand.Intn(101)))
+		}
+	case reflect.Float32, reflect.Float64:
+		value.SetFloat((g.rand.Float64() - 0.5) * 1e6)
+	}
+}
+
+func (g *Generator) fillStruct(value reflect.Value, depth int) {
+	oneofs := oneofWrappers(value)
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		// unexported fields and the XXX_ bookkeeping fields of generated messages
+		if field.PkgPath != "" || strings.HasPrefix(field.Name, "XXX_") || g.skipped(field.Name) {
+			continue
+		}
+		if _, ok := field.Tag.Lookup("protobuf_oneof"); ok {
+			g.fillOneof(value.Field(i), oneofs, depth)
+			continue
+		}
+		g.fill(value.Field(i), depth)
+	}
+}
+
+// fillOneof sets a oneof field to a random one of the wrapper types that implement its interface
+func (g *Generator) fillOneof(field reflect.Value, wrappers []reflect.Type, depth int) {
+	var options []reflect.Type
+	for _, wrapper := range wrappers {
+		if wrapper.Implements(field.Type()) {
+			options = append(options, wrapper)
+		}
+	}
+	if len(options) == 0 || depth >= g.MaxDepth {
+		return
+	}
+	wrapper := reflect.New(options[g.rand.Intn(len(options))].Elem())
+	g.fill(wrapper.Elem(), depth+1)
+	field.Set(wrapper)
+}
+
+func (g *Generator) skipped(name string) bool {
+	for _, skip := range g.SkipFields {
+		if skip == name {
+			return true
+		}
+	}
+	return false
+}
+
+// oneofWrappers returns the pointer types of the oneof wrappers of a generated message. gogo messages, and those
+// generated by golang/protobuf before 1.4, list them in XXX_OneofWrappers; newer ones are inspected with protoreflect.
+func oneofWrappers(value reflect.Value) []reflect.Type {
+	method := value.Addr().MethodByName("XXX_OneofWrappers")
+	if !method.IsValid() {
+		if msg, ok := value.Addr().Interface().(protoreflect.ProtoMessage); ok {
+			return reflectOneofWrappers(msg.ProtoReflect())
+		}
+		return nil
+	}
+	var wrappers []reflect.Type
+	for _, wrapper := range method.Call(nil)[0].Interface().([]interface{}) {
+		wrappers = append(wrappers, reflect.TypeOf(wrapper))
+	}
+	return wrappers
+}
+
+// reflectOneofWrappers finds the wrapper type of every option of msg's oneofs by setting the option on an empty
+// message, which stores the wrapper in the Go field of the oneof
+func reflectOneofWrappers(msg protoreflect.Message) []reflect.Type {
+	var wrappers []reflect.Type
+	oneofs := msg.Descriptor().Oneofs()
+	for i := 0; i < oneofs.Len(); i++ {
+		oneof := oneofs.Get(i)
+		if oneof.IsSynthetic() {
+			// proto3 optional fields, which are plain pointers in Go
+			continue
+		}
+		for j := 0; j < oneof.Fields().Len(); j++ {
+			option := oneof.Fields().Get(j)
+			set := msg.New()
+			set.Set(option, set.NewField(option))
+			if wrapper := oneofField(reflect.ValueOf(set.Interface()).Elem(), string(oneof.Name())); wrapper.IsValid() && !wrapper.IsNil() {
+				wrappers = append(wrappers, wrapper.Elem().Type())
+			}
+		}
+	}
+	return wrappers
+}
+
+// oneofField returns the field of a generated message holding the oneof with the given name
+func oneofField(value reflect.Value, name string) reflect.Value {
+	for i := 0; i < value.NumField(); i++ {
+		if value.Type().Field(i).Tag.Get("protobuf_oneof") == name {
+			return value.Field(i)
+		}
+	}
+	return reflect.Value{}
+}
+
+// generated enums name their values in String and fall back to the number for values without a name
+const maxEnumValue = 64
+
+func namedEnumValues(t reflect.Type) []int64 {
+	if t.Kind() != reflect.Int32 || !t.Implements(reflect.TypeOf((*enum)(nil)).Elem()) {
+		return nil
+	}
+	var values []int64
+	for i := int64(0); i < maxEnumValue; i++ {
+		v := reflect.New(t).Elem()
+		v.SetInt(i)
+		if v.Interface().(enum).String() != strconv.FormatInt(i, 10) {
+			values = append(values, i)
+		}
+	}
+	return values
+}
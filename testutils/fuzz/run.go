@@ -0,0 +1,126 @@
+package fuzz
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+const (
+	// SeedEnvVar reruns a fuzz test with the seed reported by a previous failure
+	SeedEnvVar = "FUZZ_SEED"
+
+	DefaultIterations = 100
+	// how many times the property may be evaluated while shrinking a failing input
+	DefaultShrinkBudget = 1000
+)
+
+// Property is the behavior under test. It returns an error if input breaks it.
+type Property func(input interface{}) error
+
+type Config struct {
+	// defaults to DefaultIterations
+	Iterations int
+	// defaults to $FUZZ_SEED, or the current time
+	Seed int64
+	// optional, configures the Generator before the first input is generated
+	Configure func(g *Generator)
+	// defaults to DefaultShrinkBudget, negative to disable shrinking
+	ShrinkBudget int
+}
+
+// FailureError is returned by Run when an input breaks the property
+type FailureError struct {
+	Seed      int64
+	Iteration int
+	// the generated input that broke the property
+	Input interface{}
+	// the smallest input found that still breaks it
+	Shrunk interface{}
+	Err    error
+}
+
+func (e *FailureError) Error() string {
+	return fmt.Sprintf("property failed on iteration %d for input %s: %v (rerun with %s=%d; original input %s)",
+		e.Iteration, describe(e.Shrunk), e.Err, SeedEnvVar, e.Seed, describe(e.Input))
+}
+
+func (e *FailureError) Unwrap() error {
+	return e.Err
+}
+
+func describe(input interface{}) string {
+	out, err := json.Marshal(input)
+	if err != nil {
+		return fmt.Sprintf("%+v", input)
+	}
+	return string(out)
+}
+
+// Run checks property against randomly filled values of the type of sample, which must be a pointer to a
+// struct such as a generated proto message. When an input breaks the property, it is shrunk to a smaller input
+// that still breaks it, and a *FailureError reporting both and the seed to reproduce them is returned, e.g.
+//
+//	Expect(fuzz.Run(&v1.VirtualService{}, fuzz.Config{}, func(input interface{}) error {
+//		_, err := translator.Translate(input.(*v1.VirtualService))
+//		return err
+//	})).To(Succeed())
+func Run(sample interface{}, config Config, property Property) error {
+	sampleType := reflect.TypeOf(sample)
+	if sampleType == nil || sampleType.Kind() != reflect.Ptr {
+		return fmt.Errorf("fuzz: sample must be a pointer, got %T", sample)
+	}
+	seed := config.Seed
+	if seed == 0 {
+		seed = seedFromEnv()
+	}
+	iterations := config.Iterations
+	if iterations <= 0 {
+		iterations = DefaultIterations
+	}
+	generator := NewGenerator(seed)
+	if config.Configure != nil {
+		config.Configure(generator)
+	}
+
+	for i := 0; i < iterations; i++ {
+		input := reflect.New(sampleType.Elem()).Interface()
+		generator.Fill(input)
+		err := check(property, input)
+		if err == nil {
+			continue
+		}
+		// shrinking modifies its input, so keep a copy of the original to report
+		original := deepCopy(reflect.ValueOf(input)).Interface()
+		budget := config.ShrinkBudget
+		if budget == 0 {
+			budget = DefaultShrinkBudget
+		}
+		if budget > 0 {
+			err = Shrink(input, property, budget)
+		}
+		return &FailureError{Seed: seed, Iteration: i, Input: original, Shrunk: input, Err: err}
+	}
+	return nil
+}
+
+func seedFromEnv() int64 {
+	if seed, err := strconv.ParseInt(os.Getenv(SeedEnvVar), 10, 64); err == nil {
+		return seed
+	}
+	return time.Now().UnixNano()
+}
+
+// check runs property on a copy of input, so that a property that modifies its argument doesn't change the input
+// that is reported and shrunk. Panics are turned into errors, since that's how a lot of robustness bugs show up.
+func check(property Property, input interface{}) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return property(deepCopy(reflect.ValueOf(input)).Interface())
+}
@@ -0,0 +1,174 @@
+package fuzz
+
+import (
+	"reflect"
+)
+
+// Shrink simplifies a failing input in place for as long as the property keeps failing on it, by unsetting fields,
+// removing elements of slices and maps, and shortening strings and numbers. At most budget evaluations of the
+// property are made. It returns the error the property returned for the final input.
+// Values of maps are only shrunk if they are pointers, e.g. to messages.
+func Shrink(input interface{}, property Property, budget int) error {
+	s := &shrinker{property: property, input: input, budget: budget}
+	s.err = check(property, input)
+	if s.err == nil {
+		return nil
+	}
+	for s.budget > 0 && s.shrink(reflect.ValueOf(input).Elem()) {
+	}
+	return s.err
+}
+
+type shrinker struct {
+	property Property
+	input    interface{}
+	budget   int
+	// the error of the last evaluation that failed
+	err error
+}
+
+func (s *shrinker) fails() bool {
+	if s.budget <= 0 {
+		return false
+	}
+	s.budget--
+	if err := check(s.property, s.input); err != nil {
+		s.err = err
+		return true
+	}
+	return false
+}
+
+// try sets target to candidate, and keeps it there if the property still fails
+func (s *shrinker) try(target, candidate reflect.Value) bool {
+	previous := reflect.New(target.Type()).Elem()
+	previous.Set(target)
+	target.Set(candidate)
+	if s.fails() {
+		return true
+	}
+	target.Set(previous)
+	return false
+}
+
+// shrink returns whether it made value smaller
+func (s *shrinker) shrink(value reflect.Value) bool {
+	if !value.CanSet() {
+		return false
+	}
+	if !isZero(value) && s.try(value, reflect.Zero(value.Type())) {
+		return true
+	}
+	progress := false
+	switch value.Kind() {
+	case reflect.Ptr:
+		if !value.IsNil() {
+			progress = s.shrink(value.Elem())
+		}
+	case reflect.Interface:
+		// a oneof, which holds a pointer to its wrapper
+		if !value.IsNil() && value.Elem().Kind() == reflect.Ptr && !value.Elem().IsNil() {
+			progress = s.shrink(value.Elem().Elem())
+		}
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			if s.shrink(value.Field(i)) {
+				progress = true
+			}
+		}
+	case reflect.Slice:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			return s.try(value, value.Slice(0, value.Len()/2))
+		}
+		for i := 0; i < value.Len(); i++ {
+			if s.try(value, without(value, i)) {
+				return true
+			}
+		}
+		for i := 0; i < value.Len(); i++ {
+			if s.shrink(value.Index(i)) {
+				progress = true
+			}
+		}
+	case reflect.Map:
+		for _, key := range value.MapKeys() {
+			if s.try(value, withoutKey(value, key)) {
+				return true
+			}
+		}
+		for _, key := range value.MapKeys() {
+			if elem := value.MapIndex(key); elem.Kind() == reflect.Ptr && !elem.IsNil() && s.shrink(elem.Elem()) {
+				progress = true
+			}
+		}
+	case reflect.String:
+		return s.try(value, reflect.ValueOf(value.String()[:value.Len()/2]).Convert(value.Type()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if namedEnumValues(value.Type()) == nil {
+			return s.try(value, reflect.ValueOf(value.Int()/2).Convert(value.Type()))
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return s.try(value, reflect.ValueOf(value.Uint()/2).Convert(value.Type()))
+	}
+	return progress
+}
+
+func isZero(value reflect.Value) bool {
+	return reflect.DeepEqual(value.Interface(), reflect.Zero(value.Type()).Interface())
+}
+
+// without returns a copy of slice without the element at index i
+func without(slice reflect.Value, i int) reflect.Value {
+	result := reflect.MakeSlice(slice.Type(), 0, slice.Len()-1)
+	result = reflect.AppendSlice(result, slice.Slice(0, i))
+	return reflect.AppendSlice(result, slice.Slice(i+1, slice.Len()))
+}
+
+// withoutKey returns a copy of m without key
+func withoutKey(m, key reflect.Value) reflect.Value {
+	result := reflect.MakeMap(m.Type())
+	for _, k := range m.MapKeys() {
+		if k.Interface() != key.Interface() {
+			result.SetMapIndex(k, m.MapIndex(k))
+		}
+	}
+	return result
+}
+
+// deepCopy copies the exported state of value, so that shrinking the original leaves the copy as it was
+func deepCopy(value reflect.Value) reflect.Value {
+	result := reflect.New(value.Type()).Elem()
+	switch value.Kind() {
+	case reflect.Ptr:
+		if !value.IsNil() {
+			result.Set(deepCopy(value.Elem()).Addr())
+		}
+	case reflect.Interface:
+		if !value.IsNil() {
+			result.Set(deepCopy(value.Elem()))
+		}
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			if result.Field(i).CanSet() {
+				result.Field(i).Set(deepCopy(value.Field(i)))
+			}
+		}
+	case reflect.Slice:
+		if !value.IsNil() {
+			result.Set(reflect.MakeSlice(value.Type(), value.Len(), value.Len()))
+			for i := 0; i < value.Len(); i++ {
+				result.Index(i).Set(deepCopy(value.Index(i)))
+			}
+		}
+	case reflect.Map:
+		if !value.IsNil() {
+			result.Set(reflect.MakeMap(value.Type()))
+			for _, key := range value.MapKeys() {
+				result.SetMapIndex(key, deepCopy(value.MapIndex(key)))
+			}
+		}
+	default:
+		result.Set(value)
+	}
+	return result
+}
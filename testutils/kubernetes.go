@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/rotisserie/eris"
+	"github.com/solo-io/go-utils/contextutils"
 	"github.com/solo-io/go-utils/log"
 	"github.com/solo-io/go-utils/threadsafe"
 
@@ -37,11 +38,15 @@ func SetupKubeForTest(namespace string) error {
 
 // Deprecated: this function is incredibly slow, use DeleteNamespacesInParallelBlocking instead
 func TeardownKube(namespace string) error {
-	return Kubectl("delete", "namespace", namespace)
+	err := Kubectl("delete", "namespace", namespace)
+	contextutils.Audit(context.Background(), "delete namespace", namespace, err)
+	return err
 }
 
 func DeleteCrd(crd string) error {
-	return Kubectl("delete", "crd", crd)
+	err := Kubectl("delete", "crd", crd)
+	contextutils.Audit(context.Background(), "delete crd", crd, err)
+	return err
 }
 
 func kubectl(args ...string) *exec.Cmd {
@@ -1,8 +1,11 @@
 package testutils
 
 import (
+	"fmt"
+
 	"github.com/fgrosse/zaptest"
 	"github.com/solo-io/go-utils/contextutils"
+	"go.uber.org/zap"
 
 	. "github.com/onsi/ginkgo"
 )
@@ -11,3 +14,32 @@ func SetupLog() {
 	logger := zaptest.LoggerWriter(GinkgoWriter)
 	contextutils.SetFallbackLogger(logger.Sugar())
 }
+
+// SetupBufferedLog is SetupLog, except that debug logs are only written, along with the errors logged, if a spec
+// fails; see contextutils.BufferedCore. size is how many of the most recent debug logs are kept. Call
+// FlushLogsOnFailure from an AfterEach to write the debug logs of failed specs, and Close the core from an
+// AfterSuite.
+func SetupBufferedLog(size int) *contextutils.BufferedCore {
+	core := contextutils.NewBufferedCore(zaptest.LoggerWriter(GinkgoWriter).Core(), size)
+	contextutils.SetFallbackLogger(zap.New(core).Sugar())
+	return core
+}
+
+// FlushLogsOnFailure writes the debug logs buffered by core if the current spec failed, and drops them otherwise.
+// Either way it waits for the spec's queued logs to be written, so that each spec only reports its own logs:
+//
+//	var _ = AfterEach(func() {
+//		testutils.FlushLogsOnFailure(logs)
+//	})
+func FlushLogsOnFailure(core *contextutils.BufferedCore) {
+	if !CurrentGinkgoTestDescription().Failed {
+		core.Discard()
+		if err := core.Sync(); err != nil {
+			fmt.Fprintf(GinkgoWriter, "unable to write logs: %v\n", err)
+		}
+		return
+	}
+	if err := core.Flush(); err != nil {
+		fmt.Fprintf(GinkgoWriter, "unable to flush debug logs: %v\n", err)
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/solo-io/go-utils/log"
+	"github.com/solo-io/go-utils/versionutils"
+)
+
+// Fails if a deprecations file removes features earlier than the deprecation policy allows, for use in CI:
+//
+//	go run github.com/solo-io/go-utils/versionutils/cmd/check-deprecations -file deprecations.yaml -minor-releases 2
+//
+// With -release, the features that can be removed in that release are listed as well.
+func main() {
+	var (
+		policy  versionutils.DeprecationPolicy
+		file    string
+		release string
+	)
+	flag.StringVar(&file, "file", "deprecations.yaml", "YAML file listing the deprecated features")
+	flag.IntVar(&policy.MinorReleases, "minor-releases", 2, "number of minor releases deprecated features are kept for")
+	flag.BoolVar(&policy.RequireMajorRemovalWhenStable, "require-major", false, "only allow removals in a new major version from v1.0.0 on")
+	flag.StringVar(&release, "release", "", "version being released, to list the features that can be removed in it")
+	flag.Parse()
+
+	deprecations, err := versionutils.ReadDeprecations(file)
+	if err != nil {
+		log.Fatalf("unable to read deprecations: %v", err)
+	}
+	if err := versionutils.CheckDeprecations(deprecations, policy); err != nil {
+		log.Fatalf("%v", err)
+	}
+	if release == "" {
+		return
+	}
+	removable, err := versionutils.RemovableDeprecations(deprecations, policy, release)
+	if err != nil {
+		log.Fatalf("unable to list removable deprecations: %v", err)
+	}
+	for _, deprecation := range removable {
+		fmt.Printf("%s (deprecated in %s) can be removed in %s\n", deprecation.Feature, deprecation.DeprecatedIn, release)
+	}
+}
@@ -0,0 +1,150 @@
+package versionutils
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	"github.com/rotisserie/eris"
+)
+
+var (
+	EarlyRemovalError = func(feature, deprecatedIn, removedIn string, earliest *Version) error {
+		return eris.Errorf("%s was deprecated in %s and can't be removed in %s, the earliest version it can be removed in is %s",
+			feature, deprecatedIn, removedIn, earliest.String())
+	}
+	RemovedBeforeDeprecatedError = func(feature, deprecatedIn, removedIn string) error {
+		return eris.Errorf("%s is removed in %s, which is not after %s, the version it was deprecated in", feature, removedIn, deprecatedIn)
+	}
+)
+
+// DeprecationPolicy says how long deprecated features and flags have to be kept before they are removed
+type DeprecationPolicy struct {
+	// the number of minor releases a deprecated feature is kept for, e.g. with 2, a feature deprecated in
+	// v1.4.x can be removed in v1.6.0
+	MinorReleases int
+	// if true, features can only be removed in a new major version once a repo is stable (v1.0.0 or later),
+	// since removing them is a breaking change
+	RequireMajorRemovalWhenStable bool
+}
+
+// EarliestRemovalVersion returns the first version a feature deprecated in deprecatedIn, e.g. "v1.4.2", can be
+// removed in under the policy
+func (p DeprecationPolicy) EarliestRemovalVersion(deprecatedIn string) (*Version, error) {
+	deprecated, err := ParseVersion(deprecatedIn)
+	if err != nil {
+		return nil, err
+	}
+	earliest := &Version{Major: deprecated.Major, Minor: deprecated.Minor + p.MinorReleases}
+	if p.MinorReleases <= 0 {
+		// the feature still has to exist in the release that deprecates it
+		earliest.Minor = deprecated.Minor
+		earliest.Patch = deprecated.Patch + 1
+		if deprecated.Label != "" {
+			earliest.Patch = deprecated.Patch
+		}
+	}
+	if p.RequireMajorRemovalWhenStable && deprecated.Major >= 1 {
+		earliest = &Version{Major: deprecated.Major + 1}
+	}
+	return earliest, nil
+}
+
+// ValidateRemoval returns an error if removing feature in removedIn is earlier than the policy allows.
+// Pre-releases of the earliest removal version, e.g. v1.6.0-beta1, are allowed, so that removals can land
+// while the release is being prepared.
+func (p DeprecationPolicy) ValidateRemoval(feature, deprecatedIn, removedIn string) error {
+	earliest, err := p.EarliestRemovalVersion(deprecatedIn)
+	if err != nil {
+		return err
+	}
+	removed, err := ParseVersion(removedIn)
+	if err != nil {
+		return err
+	}
+	deprecated, _ := ParseVersion(deprecatedIn)
+	if isAfter, _, _ := removed.IsGreaterThanPtr(deprecated); !isAfter {
+		return RemovedBeforeDeprecatedError(feature, deprecatedIn, removedIn)
+	}
+	if !isAtLeast(removed, earliest) {
+		return EarlyRemovalError(feature, deprecatedIn, removedIn, earliest)
+	}
+	return nil
+}
+
+// Deprecation records when a feature or flag was deprecated, and when it was removed once it has been
+type Deprecation struct {
+	Feature      string `json:"feature"`
+	DeprecatedIn string `json:"deprecatedIn"`
+	RemovedIn    string `json:"removedIn,omitempty"`
+}
+
+// ReadDeprecations reads a YAML list of deprecations, e.g.
+//
+//   - feature: --legacy-mode flag
+//     deprecatedIn: v1.4.0
+//     removedIn: v1.6.0
+func ReadDeprecations(path string) ([]Deprecation, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var deprecations []Deprecation
+	if err := yaml.Unmarshal(data, &deprecations); err != nil {
+		return nil, errors.Wrapf(err, "parsing deprecations file %s", path)
+	}
+	return deprecations, nil
+}
+
+// CheckDeprecations validates all the removals in deprecations against the policy, returning one error listing
+// every early removal. CI can run it on the deprecations file of a repo to fail PRs that remove features too early.
+func CheckDeprecations(deprecations []Deprecation, policy DeprecationPolicy) error {
+	var problems []string
+	for _, deprecation := range deprecations {
+		if deprecation.RemovedIn == "" {
+			if _, err := ParseVersion(deprecation.DeprecatedIn); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", deprecation.Feature, err))
+			}
+			continue
+		}
+		if err := policy.ValidateRemoval(deprecation.Feature, deprecation.DeprecatedIn, deprecation.RemovedIn); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	if len(problems) > 0 {
+		return eris.Errorf("invalid deprecations:\n%s", strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// RemovableDeprecations returns the features that haven't been removed yet and can be removed in version, e.g. to
+// list what can be cleaned up when preparing a release
+func RemovableDeprecations(deprecations []Deprecation, policy DeprecationPolicy, version string) ([]Deprecation, error) {
+	release, err := ParseVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	var removable []Deprecation
+	for _, deprecation := range deprecations {
+		if deprecation.RemovedIn != "" {
+			continue
+		}
+		earliest, err := policy.EarliestRemovalVersion(deprecation.DeprecatedIn)
+		if err != nil {
+			return nil, err
+		}
+		if isAtLeast(release, earliest) {
+			removable = append(removable, deprecation)
+		}
+	}
+	return removable, nil
+}
+
+// isAtLeast returns whether version, or the release it is a pre-release of, is at least earliest
+func isAtLeast(version, earliest *Version) bool {
+	release := Version{Major: version.Major, Minor: version.Minor, Patch: version.Patch}
+	isGtEq, _ := release.IsGreaterThanOrEqualTo(*earliest)
+	return isGtEq
+}
@@ -0,0 +1,121 @@
+package versionutils_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/solo-io/go-utils/versionutils"
+)
+
+var _ = Describe("Deprecation", func() {
+
+	policy := versionutils.DeprecationPolicy{MinorReleases: 2}
+	stablePolicy := versionutils.DeprecationPolicy{MinorReleases: 2, RequireMajorRemovalWhenStable: true}
+
+	DescribeTable("EarliestRemovalVersion",
+		func(policy versionutils.DeprecationPolicy, deprecatedIn, expected string) {
+			earliest, err := policy.EarliestRemovalVersion(deprecatedIn)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(earliest.String()).To(Equal(expected))
+		},
+		Entry("minor releases", policy, "v1.4.2", "v1.6.0"),
+		Entry("pre-release", policy, "v0.9.0-beta3", "v0.11.0"),
+		Entry("stable repo requiring a major release", stablePolicy, "v1.4.2", "v2.0.0"),
+		Entry("unstable repo requiring a major release", stablePolicy, "v0.4.2", "v0.6.0"),
+		Entry("no minor releases", versionutils.DeprecationPolicy{}, "v1.4.2", "v1.4.3"),
+		Entry("no minor releases, deprecated in a pre-release", versionutils.DeprecationPolicy{}, "v1.4.0-rc1", "v1.4.0"),
+	)
+
+	It("rejects invalid versions", func() {
+		_, err := policy.EarliestRemovalVersion("1.4.2")
+		Expect(err).To(HaveOccurred())
+		Expect(policy.ValidateRemoval("flag", "v1.4.2", "v1.6")).To(HaveOccurred())
+	})
+
+	DescribeTable("ValidateRemoval",
+		func(policy versionutils.DeprecationPolicy, deprecatedIn, removedIn string, valid bool) {
+			err := policy.ValidateRemoval("--legacy-mode", deprecatedIn, removedIn)
+			if valid {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(err).To(HaveOccurred())
+			}
+		},
+		Entry("earliest version", policy, "v1.4.2", "v1.6.0", true),
+		Entry("later version", policy, "v1.4.2", "v2.1.0", true),
+		Entry("pre-release of the earliest version", policy, "v1.4.2", "v1.6.0-beta1", true),
+		Entry("too early", policy, "v1.4.2", "v1.5.9", false),
+		Entry("before it was deprecated", policy, "v1.4.2", "v1.4.0", false),
+		Entry("minor release of a stable repo", stablePolicy, "v1.4.2", "v1.9.0", false),
+		Entry("major release of a stable repo", stablePolicy, "v1.4.2", "v2.0.0-rc1", true),
+	)
+
+	It("says when a feature can be removed", func() {
+		err := policy.ValidateRemoval("--legacy-mode", "v1.4.2", "v1.5.0")
+		Expect(err).To(MatchError("--legacy-mode was deprecated in v1.4.2 and can't be removed in v1.5.0, " +
+			"the earliest version it can be removed in is v1.6.0"))
+	})
+
+	Context("deprecations file", func() {
+
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "deprecations")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		readDeprecations := func(contents string) []versionutils.Deprecation {
+			path := filepath.Join(dir, "deprecations.yaml")
+			Expect(ioutil.WriteFile(path, []byte(contents), 0644)).To(Succeed())
+			deprecations, err := versionutils.ReadDeprecations(path)
+			Expect(err).NotTo(HaveOccurred())
+			return deprecations
+		}
+
+		It("checks every removal", func() {
+			deprecations := readDeprecations(`
+- feature: --legacy-mode flag
+  deprecatedIn: v1.4.0
+  removedIn: v1.6.0
+- feature: v1 api
+  deprecatedIn: v1.5.0
+  removedIn: v1.6.0
+- feature: --verbose flag
+  deprecatedIn: v1.5
+`)
+			Expect(deprecations).To(HaveLen(3))
+			err := versionutils.CheckDeprecations(deprecations, policy)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).NotTo(ContainSubstring("--legacy-mode"))
+			Expect(err.Error()).To(ContainSubstring("v1 api was deprecated in v1.5.0 and can't be removed in v1.6.0"))
+			Expect(err.Error()).To(ContainSubstring("--verbose flag"))
+
+			Expect(versionutils.CheckDeprecations(deprecations[:1], policy)).To(Succeed())
+		})
+
+		It("lists what can be removed in a release", func() {
+			deprecations := readDeprecations(`
+- feature: --legacy-mode flag
+  deprecatedIn: v1.4.0
+  removedIn: v1.6.0
+- feature: v1 api
+  deprecatedIn: v1.5.0
+- feature: --verbose flag
+  deprecatedIn: v1.6.0
+`)
+			removable, err := versionutils.RemovableDeprecations(deprecations, policy, "v1.7.0-beta1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(removable).To(Equal([]versionutils.Deprecation{{Feature: "v1 api", DeprecatedIn: "v1.5.0"}}))
+		})
+	})
+})